@@ -0,0 +1,83 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/pkg/gorm"
+	"github.com/si-bas/go-rest-boilerplate/pkg/migration"
+	"github.com/spf13/cobra"
+)
+
+var migrateDriver string
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Database migrations",
+	Long:  `Apply the database migrations embedded in the binary, connecting with the db configuration`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Migrate the database to the most recent version available",
+	RunE:  runMigration("up"),
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back the version by 1",
+	RunE:  runMigration("down"),
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Dump the migration status for the current database",
+	RunE:  runMigration("status"),
+}
+
+var migrateRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Re-run the latest migration",
+	RunE:  runMigration("redo"),
+}
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a new sql migration in database/<driver>",
+	Long:  `Create a new blank sql migration in database/<driver>, run it from the project root and rebuild to embed it`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migration.Create(driver(), args[0])
+	},
+}
+
+func runMigration(command string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		db := gorm.ConnectPrimaryDB()
+		defer gorm.CloseDB(db)
+
+		return migration.Run(command, db, driver())
+	}
+}
+
+func driver() string {
+	if migrateDriver != "" {
+		return migrateDriver
+	}
+
+	return gorm.Driver(config.Config.Db)
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateRedoCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
+
+	migrateCreateCmd.Flags().StringVar(&migrateDriver, "driver", "", "driver folder for the new migration (default is db.driver in the config)")
+}
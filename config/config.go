@@ -7,10 +7,17 @@ var Config *Cfg
 var TimeLocation *time.Location
 
 type Cfg struct {
-	App        AppConfig
-	Db         DB
-	Credential Credential
-	Jwt        Jwt
+	App          AppConfig
+	Db           DB
+	Credential   Credential
+	Jwt          Jwt
+	Lockout      Lockout
+	Password     Password
+	Registration Registration
+	TwoFactor    TwoFactor
+	ApiKey       ApiKey
+	Oidc         Oidc
+	Notifier     Notifier
 }
 
 type AppConfig struct {
@@ -20,15 +27,53 @@ type AppConfig struct {
 	Env      string
 	Debug    bool
 	Timezone string
+
+	// ShutdownTimeout is the grace period in seconds to drain in-flight requests
+	ShutdownTimeout int
+
+	// RequestTimeout is the deadline in seconds of a request and its queries
+	RequestTimeout int
+	// RouteTimeouts override the deadline of single routes, e.g. a slow search
+	RouteTimeouts []RouteTimeout
+
+	// ErrorFormat is json (default) or problem, with json a client still gets problem details
+	// by accepting application/problem+json
+	ErrorFormat string
+	// ProblemTypeUrl is the base of the type of problem details, about:blank when empty
+	ProblemTypeUrl string
+}
+
+type RouteTimeout struct {
+	Method string
+	// Path is the route as registered, e.g. /v1/user/:id
+	Path string
+	// Timeout in seconds
+	Timeout int
 }
 
 type DB struct {
+	// Driver is mysql (default), postgres or sqlite
+	Driver     string
 	Host       string
 	Port       int
 	Username   string
 	Password   string
 	Name       string
 	Connection DbConnConfig
+	// SslMode is only used by postgres, disable by default
+	SslMode string
+
+	// Replicas serve read queries, the primary is used for reads when empty
+	Replicas []DBReplica
+	// Policy to pick a replica: random (default), round-robin or weighted
+	Policy string
+}
+
+type DBReplica struct {
+	DB `mapstructure:",squash"`
+
+	// Weight is only used by the weighted policy
+	Weight int
 }
 
 type DbConnConfig struct {
@@ -44,6 +89,97 @@ type Credential struct {
 
 type Jwt struct {
 	Secret           string
+	SigningKey       string
+	Keys             []JwtKey
+	Issuer           string
+	Audience         string
+	Leeway           int64
 	ExpiresIn        int32
 	RefreshExpiresIn int32
+	SlidingSession   bool
+	MaxSessionAge    int32
+	Denylist         string
+}
+
+// JwtKey is an asymmetric key, a key with only a public key verifies tokens signed by a rotated out key
+type JwtKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey string
+	PublicKey  string
+}
+
+// Lockout throttles failed logins, durations are in seconds
+type Lockout struct {
+	// Driver is memory (default) or database, use database when running several replicas
+	Driver string
+	// MaxAttempts failures of an email lock it for Duration, 0 disables the lockout
+	MaxAttempts int
+	// IPMaxAttempts failures from an ip lock it for Duration, 0 disables the lockout
+	IPMaxAttempts int
+	Duration      int32
+	// BackoffBase doubles after every failure of an email up to BackoffMax, 0 disables the backoff
+	// and a BackoffMax below it keeps the delay constant
+	BackoffBase int32
+	BackoffMax  int32
+	// Window is how long a failure is remembered
+	Window int32
+}
+
+type Password struct {
+	// ResetExpiresIn is how long a reset token is valid in seconds
+	ResetExpiresIn int32
+	// ResetUrl is where the user sets the new password, the token is appended as a query parameter
+	ResetUrl string
+}
+
+type Registration struct {
+	// VerifyExpiresIn is how long an email verification token is valid in seconds
+	VerifyExpiresIn int32
+	// VerifyUrl is where the user confirms the email, the token is appended as a query parameter
+	VerifyUrl string
+	// AllowUnverifiedLogin lets users get a token before the email is verified
+	AllowUnverifiedLogin bool
+}
+
+type TwoFactor struct {
+	// Issuer is the account label shown by authenticator apps, the app name when it is not set
+	Issuer string
+	// ChallengeExpiresIn is how long the user has to send the code after the password in seconds
+	ChallengeExpiresIn int32
+	// Skew is how many periods of 30 seconds a code is accepted before and after the current one
+	Skew int
+}
+
+type ApiKey struct {
+	// Prefix starts every generated key so it is recognizable, e.g. by secret scanners
+	Prefix string
+	// MaxExpiresIn caps the lifetime of a key in seconds, keys never expire when it is 0
+	MaxExpiresIn int32
+}
+
+type Oidc struct {
+	// StateExpiresIn is how long the user has to login at the provider in seconds
+	StateExpiresIn int32
+	Providers      []OidcProvider
+}
+
+// OidcProvider is an OpenID Connect identity provider, its endpoints and keys are discovered from the issuer
+type OidcProvider struct {
+	// Name identifies the provider in the urls, e.g. /v1/auth/oidc/{name}/authorize
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	// RedirectUrl is registered at the provider, the page it points to posts the code and the state to the callback
+	RedirectUrl string
+	// Scopes are requested with openid, email and profile when it is empty
+	Scopes []string
+	// AutoRegister creates a user on the first login of a verified email no user has
+	AutoRegister bool
+}
+
+type Notifier struct {
+	// Driver is log (default), it only writes the messages to the log
+	Driver string
 }
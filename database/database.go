@@ -0,0 +1,8 @@
+package database
+
+import "embed"
+
+// Migrations holds the goose migrations of every driver, each in a folder named after the driver
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var Migrations embed.FS
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN `deleted_at` datetime NULL DEFAULT NULL AFTER `updated_at`,
+    ADD INDEX users_DELETED_AT (`deleted_at`);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP INDEX users_DELETED_AT,
+    DROP COLUMN `deleted_at`;
+
+-- +goose StatementEnd
\ No newline at end of file
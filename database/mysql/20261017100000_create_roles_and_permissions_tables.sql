@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `description` varchar(255) NOT NULL DEFAULT '',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT roles_ID PRIMARY KEY (`id`),
+    CONSTRAINT roles_NAME UNIQUE KEY (`name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `description` varchar(255) NOT NULL DEFAULT '',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT permissions_ID PRIMARY KEY (`id`),
+    CONSTRAINT permissions_NAME UNIQUE KEY (`name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE role_permissions (
+    `role_id` INT UNSIGNED NOT NULL,
+    `permission_id` INT UNSIGNED NOT NULL,
+    CONSTRAINT role_permissions_ID PRIMARY KEY (`role_id`, `permission_id`),
+    CONSTRAINT role_permissions_ROLE_ID FOREIGN KEY (`role_id`) REFERENCES roles (`id`) ON DELETE CASCADE,
+    CONSTRAINT role_permissions_PERMISSION_ID FOREIGN KEY (`permission_id`) REFERENCES permissions (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE user_roles (
+    `user_id` INT UNSIGNED NOT NULL,
+    `role_id` INT UNSIGNED NOT NULL,
+    CONSTRAINT user_roles_ID PRIMARY KEY (`user_id`, `role_id`),
+    CONSTRAINT user_roles_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE,
+    CONSTRAINT user_roles_ROLE_ID FOREIGN KEY (`role_id`) REFERENCES roles (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_roles;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE role_permissions;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE permissions;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE roles;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE refresh_tokens (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `jti` varchar(36) NOT NULL,
+    `family_id` varchar(36) NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `replaced_by` varchar(36) NULL DEFAULT NULL,
+    `expires_at` datetime NOT NULL,
+    `revoked_at` datetime NULL DEFAULT NULL,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT refresh_tokens_ID PRIMARY KEY (`id`),
+    CONSTRAINT refresh_tokens_JTI UNIQUE KEY (`jti`),
+    INDEX refresh_tokens_FAMILY_ID (`family_id`),
+    CONSTRAINT refresh_tokens_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE refresh_tokens;
+
+-- +goose StatementEnd
\ No newline at end of file
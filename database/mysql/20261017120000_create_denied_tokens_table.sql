@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE denied_tokens (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `jti` varchar(36) NULL DEFAULT NULL,
+    `user_id` INT UNSIGNED NULL DEFAULT NULL,
+    `revoked_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT denied_tokens_ID PRIMARY KEY (`id`),
+    INDEX denied_tokens_JTI (`jti`),
+    INDEX denied_tokens_USER_ID (`user_id`),
+    INDEX denied_tokens_EXPIRES_AT (`expires_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE denied_tokens;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens
+    ADD COLUMN `auth_time` datetime NULL DEFAULT NULL AFTER `user_id`;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- sessions started before the column existed are aged from their latest token
+UPDATE refresh_tokens SET auth_time = created_at;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens DROP COLUMN `auth_time`;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE login_attempts (
+    `identifier` varchar(320) NOT NULL,
+    `failures` INT UNSIGNED NOT NULL DEFAULT 0,
+    `last_failed_at` datetime NOT NULL,
+    `locked_until` datetime NULL DEFAULT NULL,
+    CONSTRAINT login_attempts_IDENTIFIER PRIMARY KEY (`identifier`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_attempts;
+
+-- +goose StatementEnd
\ No newline at end of file
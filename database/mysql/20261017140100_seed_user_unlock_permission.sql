@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('user:unlock', 'Unlock users locked out by failed logins');
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions
+WHERE roles.name = 'admin' AND permissions.name = 'user:unlock';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'user:unlock';
+
+-- +goose StatementEnd
\ No newline at end of file
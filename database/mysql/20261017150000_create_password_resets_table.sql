@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE password_resets (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime NULL DEFAULT NULL,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT password_resets_ID PRIMARY KEY (`id`),
+    CONSTRAINT password_resets_TOKEN_HASH UNIQUE KEY (`token_hash`),
+    CONSTRAINT password_resets_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE password_resets;
+
+-- +goose StatementEnd
\ No newline at end of file
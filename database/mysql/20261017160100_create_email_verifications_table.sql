@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE email_verifications (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime NULL DEFAULT NULL,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT email_verifications_ID PRIMARY KEY (`id`),
+    CONSTRAINT email_verifications_TOKEN_HASH UNIQUE KEY (`token_hash`),
+    CONSTRAINT email_verifications_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE email_verifications;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE two_factors (
+    `user_id` INT UNSIGNED NOT NULL,
+    `secret` varchar(64) NOT NULL,
+    `confirmed_at` datetime NULL DEFAULT NULL,
+    `last_used_step` BIGINT NOT NULL DEFAULT 0,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT two_factors_USER_ID PRIMARY KEY (`user_id`),
+    CONSTRAINT two_factors_USER_ID_FK FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE two_factors;
+
+-- +goose StatementEnd
\ No newline at end of file
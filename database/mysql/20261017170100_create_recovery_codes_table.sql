@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE recovery_codes (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `code_hash` varchar(64) NOT NULL,
+    `used_at` datetime NULL DEFAULT NULL,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT recovery_codes_ID PRIMARY KEY (`id`),
+    CONSTRAINT recovery_codes_USER_ID_CODE_HASH UNIQUE KEY (`user_id`, `code_hash`),
+    CONSTRAINT recovery_codes_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE recovery_codes;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_keys (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `prefix` varchar(32) NOT NULL,
+    `key_hash` varchar(64) NOT NULL,
+    `scopes` text NOT NULL,
+    `expires_at` datetime NULL DEFAULT NULL,
+    `last_used_at` datetime NULL DEFAULT NULL,
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT api_keys_ID PRIMARY KEY (`id`),
+    CONSTRAINT api_keys_KEY_HASH UNIQUE KEY (`key_hash`),
+    CONSTRAINT api_keys_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_identities (
+    `id` INT UNSIGNED auto_increment NOT NULL,
+    `user_id` INT UNSIGNED NOT NULL,
+    `provider` varchar(64) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255) NOT NULL DEFAULT '',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT user_identities_ID PRIMARY KEY (`id`),
+    CONSTRAINT user_identities_PROVIDER_SUBJECT UNIQUE KEY (`provider`, `subject`),
+    CONSTRAINT user_identities_USER_ID FOREIGN KEY (`user_id`) REFERENCES users (`id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8 COLLATE = utf8_general_ci;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_identities;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    description varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT roles_NAME UNIQUE (name)
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    description varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT permissions_NAME UNIQUE (name)
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL,
+    permission_id INTEGER NOT NULL,
+    CONSTRAINT role_permissions_ID PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT role_permissions_ROLE_ID FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+    CONSTRAINT role_permissions_PERMISSION_ID FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL,
+    role_id INTEGER NOT NULL,
+    CONSTRAINT user_roles_ID PRIMARY KEY (user_id, role_id),
+    CONSTRAINT user_roles_USER_ID FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT user_roles_ROLE_ID FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_roles;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE role_permissions;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE permissions;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DROP TABLE roles;
+
+-- +goose StatementEnd
\ No newline at end of file
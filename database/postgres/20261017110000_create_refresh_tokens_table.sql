@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    jti varchar(36) NOT NULL,
+    family_id varchar(36) NOT NULL,
+    user_id INTEGER NOT NULL,
+    replaced_by varchar(36) NULL DEFAULT NULL,
+    expires_at timestamp NOT NULL,
+    revoked_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT refresh_tokens_JTI UNIQUE (jti),
+    CONSTRAINT refresh_tokens_USER_ID FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX refresh_tokens_FAMILY_ID ON refresh_tokens (family_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE refresh_tokens;
+
+-- +goose StatementEnd
\ No newline at end of file
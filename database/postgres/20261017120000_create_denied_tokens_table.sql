@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE denied_tokens (
+    id SERIAL PRIMARY KEY,
+    jti varchar(36) NULL DEFAULT NULL,
+    user_id INTEGER NULL DEFAULT NULL,
+    revoked_at timestamp NOT NULL,
+    expires_at timestamp NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX denied_tokens_JTI ON denied_tokens (jti);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX denied_tokens_USER_ID ON denied_tokens (user_id);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX denied_tokens_EXPIRES_AT ON denied_tokens (expires_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE denied_tokens;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE login_attempts (
+    identifier varchar(320) NOT NULL PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failed_at timestamp NOT NULL,
+    locked_until timestamp NULL DEFAULT NULL
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_attempts;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE password_resets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT password_resets_TOKEN_HASH UNIQUE (token_hash),
+    CONSTRAINT password_resets_USER_ID FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX password_resets_USER_ID ON password_resets (user_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE password_resets;
+
+-- +goose StatementEnd
\ No newline at end of file
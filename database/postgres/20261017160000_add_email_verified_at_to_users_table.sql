@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN email_verified_at timestamp NULL DEFAULT NULL;
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- users created before self registration were added by an admin, they are trusted as verified
+UPDATE users SET email_verified_at = created_at;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN email_verified_at;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE oidc_states (
+    id SERIAL PRIMARY KEY,
+    state_hash varchar(64) NOT NULL,
+    provider varchar(64) NOT NULL,
+    nonce varchar(64) NOT NULL,
+    code_verifier varchar(128) NOT NULL,
+    expires_at timestamp NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT oidc_states_STATE_HASH UNIQUE (state_hash)
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE oidc_states;
+
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sessions (
+    id varchar(36) NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    user_agent varchar(512) NOT NULL DEFAULT '',
+    ip_address varchar(45) NOT NULL DEFAULT '',
+    last_seen_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at timestamp NOT NULL,
+    revoked_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT sessions_USER_ID FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX sessions_USER_ID ON sessions (user_id);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO sessions (id, user_id, last_seen_at, expires_at, revoked_at, created_at, updated_at)
+SELECT family_id, MIN(user_id), MAX(created_at), MAX(expires_at),
+    CASE WHEN SUM(CASE WHEN revoked_at IS NULL THEN 1 ELSE 0 END) = 0 THEN MAX(revoked_at) ELSE NULL END,
+    MIN(auth_time), MAX(created_at)
+FROM refresh_tokens
+GROUP BY family_id;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sessions;
+
+-- +goose StatementEnd
\ No newline at end of file
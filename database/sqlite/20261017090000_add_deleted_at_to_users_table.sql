@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deleted_at datetime NULL DEFAULT NULL;
+CREATE INDEX users_DELETED_AT ON users (deleted_at);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_DELETED_AT;
+ALTER TABLE users DROP COLUMN deleted_at;
+
+-- +goose StatementEnd
\ No newline at end of file
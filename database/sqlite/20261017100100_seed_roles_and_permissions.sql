@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('user:create', 'Create users'),
+    ('user:read', 'List and view users'),
+    ('user:update', 'Update users'),
+    ('user:delete', 'Delete users'),
+    ('user:restore', 'Restore deleted users'),
+    ('user:read_deleted', 'List deleted users'),
+    ('role:read', 'List roles and permissions'),
+    ('role:assign', 'Assign roles to users');
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access to every resource'),
+    ('member', 'Read only access to users');
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions
+WHERE roles.name = 'admin';
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions
+WHERE roles.name = 'member' AND permissions.name = 'user:read';
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+-- every existing user could call every route before roles existed
+INSERT INTO user_roles (user_id, role_id)
+SELECT users.id, roles.id FROM users, roles
+WHERE roles.name = 'admin';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM roles WHERE name IN ('admin', 'member');
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN (
+    'user:create', 'user:read', 'user:update', 'user:delete', 'user:restore', 'user:read_deleted', 'role:read', 'role:assign'
+);
+
+-- +goose StatementEnd
\ No newline at end of file
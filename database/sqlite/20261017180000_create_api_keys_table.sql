@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_keys (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name varchar(255) NOT NULL,
+    prefix varchar(32) NOT NULL,
+    key_hash varchar(64) NOT NULL,
+    scopes text NOT NULL,
+    expires_at datetime NULL DEFAULT NULL,
+    last_used_at datetime NULL DEFAULT NULL,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT api_keys_KEY_HASH UNIQUE (key_hash),
+    CONSTRAINT api_keys_USER_ID FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX api_keys_USER_ID ON api_keys (user_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+
+-- +goose StatementEnd
\ No newline at end of file
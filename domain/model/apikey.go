@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// ApiKey authenticates a machine client on behalf of its owner, only the sha256 of the key is kept.
+// Scopes are permission names, the key is granted those its owner still holds.
+type ApiKey struct {
+	ID         uint32     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint32     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CreateApiKeyResponse is the only time the key is returned
+type CreateApiKeyResponse struct {
+	ApiKey
+	Key string `json:"key"`
+}
+
+type ApiKeyFind struct {
+	ID uint32 `uri:"id" binding:"required"`
+}
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type PatchApiKeyRequest struct {
+	Name      *string    `json:"name" binding:"omitempty,min=1"`
+	Scopes    *[]string  `json:"scopes" binding:"omitempty,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ApiKeyPrincipal is who a request authenticated with an api key acts as
+type ApiKeyPrincipal struct {
+	ApiKeyID    uint32
+	UserID      uint32
+	Permissions []string
+}
@@ -1,15 +1,47 @@
 package model
 
+import "time"
+
 type ValidateUser struct {
 	Email    string
 	Password string
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,password"`
+}
+
+type RegisterRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 type JwtToken struct {
-	AccessToken      string `json:"access_token"`
-	ExpiresIn        int32  `json:"expires_in"`
-	RefreshToken     string `json:"refresh_token"`
-	RefreshExpiresIn int32  `json:"refresh_expires_in"`
+	AccessToken      string    `json:"access_token"`
+	ExpiresIn        int32     `json:"expires_in"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresIn int32     `json:"refresh_expires_in"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
 type AuthTokenRequest struct {
@@ -20,3 +52,110 @@ type AuthTokenRequest struct {
 type AuthRefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+type RefreshToken struct {
+	ID         uint32 `gorm:"primaryKey;autoIncrement"`
+	Jti        string
+	FamilyID   string
+	UserID     uint32
+	AuthTime   time.Time
+	ReplacedBy *string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// DeniedToken is either a single revoked token by its jti,
+// or every token of a user issued at or before RevokedAt
+type DeniedToken struct {
+	ID        uint32 `gorm:"primaryKey;autoIncrement"`
+	Jti       *string
+	UserID    *uint32
+	RevokedAt time.Time
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// LoginAttempt counts the failed logins of an identifier, an email or an ip
+type LoginAttempt struct {
+	Identifier   string `gorm:"primaryKey"`
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+type UnlockUserRequest struct {
+	IP string `json:"ip"`
+}
+
+type LockoutNotice struct {
+	RetryAfter  int64     `json:"retry_after"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// PasswordReset keeps only the sha256 of the token sent to the user
+type PasswordReset struct {
+	ID        uint32 `gorm:"primaryKey;autoIncrement"`
+	UserID    uint32
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerification keeps only the sha256 of the token sent to the user
+type EmailVerification struct {
+	ID        uint32 `gorm:"primaryKey;autoIncrement"`
+	UserID    uint32
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TwoFactor is the TOTP secret of a user, 2FA is only enabled once it is confirmed.
+// LastUsedStep refuses a code that was already used.
+type TwoFactor struct {
+	UserID       uint32 `gorm:"primaryKey"`
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode keeps only the sha256 of a one-time code that replaces a TOTP code
+type RecoveryCode struct {
+	ID        uint32 `gorm:"primaryKey;autoIncrement"`
+	UserID    uint32
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+}
+
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallenge is returned instead of a JwtToken when the password is valid but 2FA is enabled
+type TwoFactorChallenge struct {
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresIn      int32     `json:"expires_in"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorVerifyRequest takes a TOTP code or a recovery code
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
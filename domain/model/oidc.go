@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// UserIdentity links the account of an external identity provider to a user, the subject is unique per provider
+type UserIdentity struct {
+	ID        uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint32    `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OidcState is a pending login at a provider, only the sha256 of the state is kept.
+// The nonce and the code verifier never leave the server until the code is exchanged.
+type OidcState struct {
+	ID           uint32
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type OidcProviderFind struct {
+	Provider string `uri:"provider" binding:"required"`
+}
+
+type OidcCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type OidcAuthorization struct {
+	AuthorizationUrl string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresIn        int64  `json:"expires_in"`
+}
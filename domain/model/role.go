@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+type Role struct {
+	ID          uint32       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type Permission struct {
+	ID          uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type UserRole struct {
+	UserID uint32 `gorm:"primaryKey"`
+	RoleID uint32 `gorm:"primaryKey"`
+}
+
+// PermissionNames to get the distinct permission names granted by the roles
+func PermissionNames(roles []Role) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if seen[permission.Name] {
+				continue
+			}
+			seen[permission.Name] = true
+			names = append(names, permission.Name)
+		}
+	}
+
+	return names
+}
+
+// RoleNames to get the names of the roles
+func RoleNames(roles []Role) []string {
+	names := []string{}
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
+
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
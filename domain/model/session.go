@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Session is a login on a device, its id is the family of the refresh tokens rotated from it
+// and the sid claim of the access tokens issued to it
+type Session struct {
+	ID         string     `gorm:"primaryKey" json:"id"`
+	UserID     uint32     `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IpAddress  string     `json:"ip_address"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Current tells the session of the token the list was requested with
+	Current bool `gorm:"-" json:"current"`
+}
+
+type SessionFind struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+type UserSessionFind struct {
+	ID        uint32 `uri:"id" binding:"required"`
+	SessionID string `uri:"session" binding:"required"`
+}
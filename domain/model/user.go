@@ -8,15 +8,22 @@ import (
 )
 
 type User struct {
-	ID        uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint32         `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name            string         `json:"name"`
+	Email           string         `json:"email"`
+	Password        string         `json:"-"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
+	return u.HashPassword()
+}
+
+// HashPassword replaces the plain password with its bcrypt hash
+func (u *User) HashPassword() (err error) {
 	password := []byte(u.Password)
 
 	// Hashing the password with the default cost of 10
@@ -45,6 +52,9 @@ type UserFilter struct {
 	Keyword string `query:"q" form:"q" url:"q" json:"keyword"`
 	Name    string `query:"name" form:"name" url:"name" json:"name"`
 	Email   string `query:"email" form:"email" url:"email" json:"email"`
+
+	// IncludeDeleted also returns soft deleted users, meant for admins only
+	IncludeDeleted bool `query:"include_deleted" form:"include_deleted" url:"include_deleted" json:"include_deleted"`
 }
 
 type UserListRequest struct {
@@ -63,7 +73,25 @@ type CreateUser struct {
 type CreateUserRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=5"`
+	Password string `json:"password" binding:"required,password"`
+}
+
+type UpdateUser struct {
+	Name     *string
+	Email    *string
+	Password *string
+}
+
+type UpdateUserRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"omitempty,password"`
+}
+
+type PatchUserRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Password *string `json:"password" binding:"omitempty,password"`
 }
 
 type CreateUserResponse struct {
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository interface {
+	Insert(context.Context, *model.ApiKey) error
+	GetByUserId(context.Context, uint32) ([]model.ApiKey, error)
+	FindByUserIdAndId(context.Context, uint32, uint32) (*model.ApiKey, error)
+	FindByKeyHash(context.Context, string) (*model.ApiKey, error)
+	Update(context.Context, *model.ApiKey) error
+	Delete(context.Context, uint32) error
+	TouchLastUsed(context.Context, uint32, time.Time) error
+}
+
+type apiKeyImpl struct {
+	db *gorm.DB
+}
+
+func NewApiKeyRepository(db *gorm.DB) ApiKeyRepository {
+	return &apiKeyImpl{
+		db: db,
+	}
+}
+
+func (r *apiKeyImpl) Insert(ctx context.Context, apiKey *model.ApiKey) error {
+	err := conn(ctx, r.db).Create(apiKey).Error
+
+	return translate(err, "api key")
+}
+
+func (r *apiKeyImpl) GetByUserId(ctx context.Context, userId uint32) ([]model.ApiKey, error) {
+	var apiKeys []model.ApiKey
+	err := conn(ctx, r.db).Model(&model.ApiKey{}).Where("user_id = ?", userId).Order("id").Find(&apiKeys).Error
+
+	return apiKeys, translate(err, "api key")
+}
+
+func (r *apiKeyImpl) FindByUserIdAndId(ctx context.Context, userId uint32, id uint32) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	if err := conn(ctx, r.db).Model(&model.ApiKey{}).Where("user_id = ? AND id = ?", userId, id).First(&apiKey).Error; err != nil {
+		return nil, translate(err, "api key")
+	}
+
+	return &apiKey, nil
+}
+
+func (r *apiKeyImpl) FindByKeyHash(ctx context.Context, keyHash string) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	if err := conn(ctx, r.db).Model(&model.ApiKey{}).Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, translate(err, "api key")
+	}
+
+	return &apiKey, nil
+}
+
+func (r *apiKeyImpl) Update(ctx context.Context, apiKey *model.ApiKey) error {
+	err := conn(ctx, r.db).Save(apiKey).Error
+
+	return translate(err, "api key")
+}
+
+func (r *apiKeyImpl) Delete(ctx context.Context, id uint32) error {
+	err := conn(ctx, r.db).Delete(&model.ApiKey{}, id).Error
+
+	return translate(err, "api key")
+}
+
+// TouchLastUsed only sets the time the key was used, the other columns and updated_at are left as they are
+func (r *apiKeyImpl) TouchLastUsed(ctx context.Context, id uint32, usedAt time.Time) error {
+	err := conn(ctx, r.db).Model(&model.ApiKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+
+	return translate(err, "api key")
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository interface {
+	Insert(context.Context, *model.EmailVerification) error
+	FindByTokenHash(context.Context, string) (*model.EmailVerification, error)
+	MarkUsed(context.Context, uint32) error
+	DeleteByUserId(context.Context, uint32) error
+}
+
+type emailVerificationImpl struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationImpl{
+		db: db,
+	}
+}
+
+func (r *emailVerificationImpl) Insert(ctx context.Context, emailVerification *model.EmailVerification) error {
+	err := conn(ctx, r.db).Create(emailVerification).Error
+
+	return translate(err, "email verification")
+}
+
+func (r *emailVerificationImpl) FindByTokenHash(ctx context.Context, tokenHash string) (*model.EmailVerification, error) {
+	var emailVerification model.EmailVerification
+	if err := conn(ctx, r.db).Model(&model.EmailVerification{}).Where("token_hash = ?", tokenHash).First(&emailVerification).Error; err != nil {
+		return nil, translate(err, "email verification")
+	}
+
+	return &emailVerification, nil
+}
+
+// MarkUsed consumes the token, it returns gorm.ErrRecordNotFound when it was already used, e.g. by a concurrent verification
+func (r *emailVerificationImpl) MarkUsed(ctx context.Context, id uint32) error {
+	result := conn(ctx, r.db).Model(&model.EmailVerification{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return translate(result.Error, "email verification")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "email verification")
+	}
+
+	return nil
+}
+
+func (r *emailVerificationImpl) DeleteByUserId(ctx context.Context, userId uint32) error {
+	err := conn(ctx, r.db).Where("user_id = ?", userId).Delete(&model.EmailVerification{}).Error
+
+	return translate(err, "email verification")
+}
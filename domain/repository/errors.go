@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"gorm.io/gorm"
+)
+
+// translate turns a gorm or driver error into a domain error of its kind, the entity names the record
+// in the message. The cause is kept so errors.Is(err, gorm.ErrRecordNotFound) still holds.
+func translate(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return custErr.ErrChain{Message: entity + " not found", Cause: err, Type: custErr.ErrNotFound}
+	}
+
+	switch constraintKind(err) {
+	case custErr.ErrConflict:
+		return custErr.ErrChain{Message: entity + " already exists or is still referenced", Cause: err, Type: custErr.ErrConflict}
+	case custErr.ErrValidation:
+		return custErr.ErrChain{Message: entity + " is invalid", Cause: err, Type: custErr.ErrValidation}
+	}
+
+	return err
+}
+
+// constraintKind classifies the constraint violations of mysql, postgres and sqlite
+func constraintKind(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		// duplicate entry, row still referenced
+		case 1062, 1451:
+			return custErr.ErrConflict
+		// referenced row missing, data too long
+		case 1452, 1406:
+			return custErr.ErrValidation
+		}
+		return nil
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		// unique violation, foreign key violation
+		case "23505", "23503":
+			return custErr.ErrConflict
+		// string data right truncation
+		case "22001":
+			return custErr.ErrValidation
+		}
+		return nil
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey, sqlite3.ErrConstraintForeignKey:
+			return custErr.ErrConflict
+		}
+	}
+
+	return nil
+}
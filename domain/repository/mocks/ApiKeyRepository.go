@@ -0,0 +1,157 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/si-bas/go-rest-boilerplate/domain/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ApiKeyRepository is an autogenerated mock type for the ApiKeyRepository type
+type ApiKeyRepository struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: _a0, _a1
+func (_m *ApiKeyRepository) Delete(_a0 context.Context, _a1 uint32) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByKeyHash provides a mock function with given fields: _a0, _a1
+func (_m *ApiKeyRepository) FindByKeyHash(_a0 context.Context, _a1 string) (*model.ApiKey, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *model.ApiKey
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.ApiKey); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByUserIdAndId provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ApiKeyRepository) FindByUserIdAndId(_a0 context.Context, _a1 uint32, _a2 uint32) (*model.ApiKey, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.ApiKey
+	if rf, ok := ret.Get(0).(func(context.Context, uint32, uint32) *model.ApiKey); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint32, uint32) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUserId provides a mock function with given fields: _a0, _a1
+func (_m *ApiKeyRepository) GetByUserId(_a0 context.Context, _a1 uint32) ([]model.ApiKey, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.ApiKey
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) []model.ApiKey); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.ApiKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint32) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: _a0, _a1
+func (_m *ApiKeyRepository) Insert(_a0 context.Context, _a1 *model.ApiKey) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ApiKey) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchLastUsed provides a mock function with given fields: _a0, _a1, _a2
+func (_m *ApiKeyRepository) TouchLastUsed(_a0 context.Context, _a1 uint32, _a2 time.Time) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32, time.Time) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *ApiKeyRepository) Update(_a0 context.Context, _a1 *model.ApiKey) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ApiKey) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewApiKeyRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewApiKeyRepository creates a new instance of ApiKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewApiKeyRepository(t mockConstructorTestingTNewApiKeyRepository) *ApiKeyRepository {
+	mock := &ApiKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
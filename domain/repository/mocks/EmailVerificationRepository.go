@@ -0,0 +1,95 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/si-bas/go-rest-boilerplate/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EmailVerificationRepository is an autogenerated mock type for the EmailVerificationRepository type
+type EmailVerificationRepository struct {
+	mock.Mock
+}
+
+// DeleteByUserId provides a mock function with given fields: _a0, _a1
+func (_m *EmailVerificationRepository) DeleteByUserId(_a0 context.Context, _a1 uint32) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByTokenHash provides a mock function with given fields: _a0, _a1
+func (_m *EmailVerificationRepository) FindByTokenHash(_a0 context.Context, _a1 string) (*model.EmailVerification, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *model.EmailVerification
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.EmailVerification); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EmailVerification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: _a0, _a1
+func (_m *EmailVerificationRepository) Insert(_a0 context.Context, _a1 *model.EmailVerification) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.EmailVerification) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkUsed provides a mock function with given fields: _a0, _a1
+func (_m *EmailVerificationRepository) MarkUsed(_a0 context.Context, _a1 uint32) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewEmailVerificationRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewEmailVerificationRepository creates a new instance of EmailVerificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewEmailVerificationRepository(t mockConstructorTestingTNewEmailVerificationRepository) *EmailVerificationRepository {
+	mock := &EmailVerificationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
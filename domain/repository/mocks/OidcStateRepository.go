@@ -0,0 +1,81 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/si-bas/go-rest-boilerplate/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OidcStateRepository is an autogenerated mock type for the OidcStateRepository type
+type OidcStateRepository struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: _a0, _a1
+func (_m *OidcStateRepository) Delete(_a0 context.Context, _a1 uint32) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByStateHash provides a mock function with given fields: _a0, _a1
+func (_m *OidcStateRepository) FindByStateHash(_a0 context.Context, _a1 string) (*model.OidcState, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *model.OidcState
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.OidcState); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OidcState)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: _a0, _a1
+func (_m *OidcStateRepository) Insert(_a0 context.Context, _a1 *model.OidcState) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.OidcState) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewOidcStateRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewOidcStateRepository creates a new instance of OidcStateRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewOidcStateRepository(t mockConstructorTestingTNewOidcStateRepository) *OidcStateRepository {
+	mock := &OidcStateRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,53 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/si-bas/go-rest-boilerplate/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PermissionRepository is an autogenerated mock type for the PermissionRepository type
+type PermissionRepository struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: _a0
+func (_m *PermissionRepository) GetAll(_a0 context.Context) ([]model.Permission, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []model.Permission
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Permission); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Permission)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewPermissionRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPermissionRepository creates a new instance of PermissionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPermissionRepository(t mockConstructorTestingTNewPermissionRepository) *PermissionRepository {
+	mock := &PermissionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
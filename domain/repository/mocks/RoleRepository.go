@@ -0,0 +1,113 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/si-bas/go-rest-boilerplate/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RoleRepository is an autogenerated mock type for the RoleRepository type
+type RoleRepository struct {
+	mock.Mock
+}
+
+// GetAll provides a mock function with given fields: _a0
+func (_m *RoleRepository) GetAll(_a0 context.Context) ([]model.Role, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []model.Role
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Role); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByNames provides a mock function with given fields: _a0, _a1
+func (_m *RoleRepository) GetByNames(_a0 context.Context, _a1 []string) ([]model.Role, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.Role
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []model.Role); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUserId provides a mock function with given fields: _a0, _a1
+func (_m *RoleRepository) GetByUserId(_a0 context.Context, _a1 uint32) ([]model.Role, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []model.Role
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) []model.Role); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint32) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUserRoles provides a mock function with given fields: _a0, _a1, _a2
+func (_m *RoleRepository) SetUserRoles(_a0 context.Context, _a1 uint32, _a2 []uint32) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32, []uint32) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewRoleRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewRoleRepository creates a new instance of RoleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewRoleRepository(t mockConstructorTestingTNewRoleRepository) *RoleRepository {
+	mock := &RoleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
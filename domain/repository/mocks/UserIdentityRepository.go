@@ -0,0 +1,67 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/si-bas/go-rest-boilerplate/domain/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserIdentityRepository is an autogenerated mock type for the UserIdentityRepository type
+type UserIdentityRepository struct {
+	mock.Mock
+}
+
+// FindByProviderAndSubject provides a mock function with given fields: _a0, _a1, _a2
+func (_m *UserIdentityRepository) FindByProviderAndSubject(_a0 context.Context, _a1 string, _a2 string) (*model.UserIdentity, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.UserIdentity
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.UserIdentity); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserIdentity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: _a0, _a1
+func (_m *UserIdentityRepository) Insert(_a0 context.Context, _a1 *model.UserIdentity) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.UserIdentity) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewUserIdentityRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewUserIdentityRepository creates a new instance of UserIdentityRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewUserIdentityRepository(t mockConstructorTestingTNewUserIdentityRepository) *UserIdentityRepository {
+	mock := &UserIdentityRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
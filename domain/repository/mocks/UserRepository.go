@@ -42,13 +42,27 @@ func (_m *UserRepository) CountByEmail(_a0 context.Context, _a1 string) (*int64,
 	return r0, r1
 }
 
-// FilteredDb provides a mock function with given fields: _a0
-func (_m *UserRepository) FilteredDb(_a0 model.UserFilter) *gorm.DB {
-	ret := _m.Called(_a0)
+// Delete provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Delete(_a0 context.Context, _a1 uint32) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FilteredDb provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) FilteredDb(_a0 context.Context, _a1 model.UserFilter) *gorm.DB {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *gorm.DB
-	if rf, ok := ret.Get(0).(func(model.UserFilter) *gorm.DB); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserFilter) *gorm.DB); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*gorm.DB)
@@ -104,6 +118,29 @@ func (_m *UserRepository) FindById(_a0 context.Context, _a1 uint32) (*model.User
 	return r0, r1
 }
 
+// FindByIdWithDeleted provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) FindByIdWithDeleted(_a0 context.Context, _a1 uint32) (*model.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) *model.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint32) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFiltered provides a mock function with given fields: _a0, _a1
 func (_m *UserRepository) GetFiltered(_a0 context.Context, _a1 model.UserFilter) ([]model.User, error) {
 	ret := _m.Called(_a0, _a1)
@@ -173,6 +210,34 @@ func (_m *UserRepository) Insert(_a0 context.Context, _a1 *model.User) error {
 	return r0
 }
 
+// Restore provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Restore(_a0 context.Context, _a1 uint32) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint32) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *UserRepository) Update(_a0 context.Context, _a1 *model.User) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.User) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewUserRepository interface {
 	mock.TestingT
 	Cleanup(func())
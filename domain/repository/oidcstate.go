@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type OidcStateRepository interface {
+	Insert(context.Context, *model.OidcState) error
+	FindByStateHash(context.Context, string) (*model.OidcState, error)
+	Delete(context.Context, uint32) error
+}
+
+type oidcStateImpl struct {
+	db *gorm.DB
+}
+
+func NewOidcStateRepository(db *gorm.DB) OidcStateRepository {
+	return &oidcStateImpl{
+		db: db,
+	}
+}
+
+func (r *oidcStateImpl) Insert(ctx context.Context, oidcState *model.OidcState) error {
+	err := conn(ctx, r.db).Create(oidcState).Error
+
+	return translate(err, "login state")
+}
+
+func (r *oidcStateImpl) FindByStateHash(ctx context.Context, stateHash string) (*model.OidcState, error) {
+	var oidcState model.OidcState
+	if err := conn(ctx, r.db).Model(&model.OidcState{}).Where("state_hash = ?", stateHash).First(&oidcState).Error; err != nil {
+		return nil, translate(err, "login state")
+	}
+
+	return &oidcState, nil
+}
+
+// Delete consumes the state, it returns gorm.ErrRecordNotFound when it was already used, e.g. by a concurrent callback
+func (r *oidcStateImpl) Delete(ctx context.Context, id uint32) error {
+	result := conn(ctx, r.db).Delete(&model.OidcState{}, id)
+	if result.Error != nil {
+		return translate(result.Error, "login state")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "login state")
+	}
+
+	return nil
+}
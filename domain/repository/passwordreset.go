@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Insert(context.Context, *model.PasswordReset) error
+	FindByTokenHash(context.Context, string) (*model.PasswordReset, error)
+	MarkUsed(context.Context, uint32) error
+	DeleteByUserId(context.Context, uint32) error
+}
+
+type passwordResetImpl struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetImpl{
+		db: db,
+	}
+}
+
+func (r *passwordResetImpl) Insert(ctx context.Context, passwordReset *model.PasswordReset) error {
+	err := conn(ctx, r.db).Create(passwordReset).Error
+
+	return translate(err, "password reset")
+}
+
+func (r *passwordResetImpl) FindByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordReset, error) {
+	var passwordReset model.PasswordReset
+	if err := conn(ctx, r.db).Model(&model.PasswordReset{}).Where("token_hash = ?", tokenHash).First(&passwordReset).Error; err != nil {
+		return nil, translate(err, "password reset")
+	}
+
+	return &passwordReset, nil
+}
+
+// MarkUsed consumes the token, it returns gorm.ErrRecordNotFound when it was already used, e.g. by a concurrent reset
+func (r *passwordResetImpl) MarkUsed(ctx context.Context, id uint32) error {
+	result := conn(ctx, r.db).Model(&model.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return translate(result.Error, "password reset")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "password reset")
+	}
+
+	return nil
+}
+
+func (r *passwordResetImpl) DeleteByUserId(ctx context.Context, userId uint32) error {
+	err := conn(ctx, r.db).Where("user_id = ?", userId).Delete(&model.PasswordReset{}).Error
+
+	return translate(err, "password reset")
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type PermissionRepository interface {
+	GetAll(context.Context) ([]model.Permission, error)
+}
+
+type permissionImpl struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionImpl{
+		db: db,
+	}
+}
+
+func (r *permissionImpl) GetAll(ctx context.Context) ([]model.Permission, error) {
+	var permissions []model.Permission
+	err := conn(ctx, r.db).Model(&model.Permission{}).Order("id ASC").Find(&permissions).Error
+
+	return permissions, translate(err, "permission")
+}
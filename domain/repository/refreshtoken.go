@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Insert(context.Context, *model.RefreshToken) error
+	FindByJti(context.Context, string) (*model.RefreshToken, error)
+	Rotate(context.Context, string, string) error
+	RevokeFamily(context.Context, string) error
+	RevokeByUserId(context.Context, uint32) error
+}
+
+type refreshTokenImpl struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenImpl{
+		db: db,
+	}
+}
+
+func (r *refreshTokenImpl) Insert(ctx context.Context, refreshToken *model.RefreshToken) error {
+	err := conn(ctx, r.db).Create(refreshToken).Error
+
+	return translate(err, "refresh token")
+}
+
+func (r *refreshTokenImpl) FindByJti(ctx context.Context, jti string) (*model.RefreshToken, error) {
+	var refreshToken model.RefreshToken
+	if err := conn(ctx, r.db).Model(&model.RefreshToken{}).Where("jti = ?", jti).First(&refreshToken).Error; err != nil {
+		return nil, translate(err, "refresh token")
+	}
+
+	return &refreshToken, nil
+}
+
+// Rotate revokes the token and records its successor, it returns gorm.ErrRecordNotFound
+// when the token was already revoked, e.g. by a concurrent refresh
+func (r *refreshTokenImpl) Rotate(ctx context.Context, jti string, replacedBy string) error {
+	result := conn(ctx, r.db).Model(&model.RefreshToken{}).
+		Where("jti = ? AND revoked_at IS NULL", jti).
+		Updates(map[string]interface{}{
+			"revoked_at":  time.Now(),
+			"replaced_by": replacedBy,
+		})
+	if result.Error != nil {
+		return translate(result.Error, "refresh token")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "refresh token")
+	}
+
+	return nil
+}
+
+func (r *refreshTokenImpl) RevokeFamily(ctx context.Context, familyId string) error {
+	err := conn(ctx, r.db).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now()).Error
+
+	return translate(err, "refresh token")
+}
+
+func (r *refreshTokenImpl) RevokeByUserId(ctx context.Context, userId uint32) error {
+	err := conn(ctx, r.db).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+
+	return translate(err, "refresh token")
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type key string
+
+const (
+	// usePrimaryKey is reserved name in the context to skip the read replicas
+	usePrimaryKey key = "use_primary"
+
+	// txKey is reserved name in the context to hold the transaction of TxManager
+	txKey key = "transaction"
+)
+
+// WithPrimary to make repository reads go to the primary database instead of a replica,
+// used by read-after-write paths that cannot tolerate replication lag
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey, true)
+}
+
+// IsPrimary tells whether the reads of ctx go to the primary database, see WithPrimary
+func IsPrimary(ctx context.Context) bool {
+	usePrimary, ok := ctx.Value(usePrimaryKey).(bool)
+	return ok && usePrimary
+}
+
+// conn to get the db handle for ctx, reads go to the replicas unless WithPrimary is set
+// and every query runs in the transaction of ctx when there is one.
+// The queries are bound to ctx so they are cancelled with the request.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	db = db.WithContext(ctx)
+	if IsPrimary(ctx) {
+		return db.Clauses(dbresolver.Write)
+	}
+
+	return db
+}
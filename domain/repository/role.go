@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	GetAll(context.Context) ([]model.Role, error)
+	GetByNames(context.Context, []string) ([]model.Role, error)
+	GetByUserId(context.Context, uint32) ([]model.Role, error)
+	SetUserRoles(context.Context, uint32, []uint32) error
+}
+
+type roleImpl struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleImpl{
+		db: db,
+	}
+}
+
+func (r *roleImpl) GetAll(ctx context.Context) ([]model.Role, error) {
+	var roles []model.Role
+	err := conn(ctx, r.db).Model(&model.Role{}).Preload("Permissions").Order("id ASC").Find(&roles).Error
+
+	return roles, translate(err, "role")
+}
+
+func (r *roleImpl) GetByNames(ctx context.Context, names []string) ([]model.Role, error) {
+	var roles []model.Role
+	err := conn(ctx, r.db).Model(&model.Role{}).Where("name IN ?", names).Find(&roles).Error
+
+	return roles, translate(err, "role")
+}
+
+func (r *roleImpl) GetByUserId(ctx context.Context, userId uint32) ([]model.Role, error) {
+	var roles []model.Role
+	err := conn(ctx, r.db).Model(&model.Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userId).
+		Preload("Permissions").
+		Order("roles.id ASC").
+		Find(&roles).Error
+
+	return roles, translate(err, "role")
+}
+
+// SetUserRoles replaces every role of the user with the given roles
+func (r *roleImpl) SetUserRoles(ctx context.Context, userId uint32, roleIds []uint32) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+
+		if len(roleIds) == 0 {
+			return nil
+		}
+
+		userRoles := make([]model.UserRole, 0, len(roleIds))
+		for _, roleId := range roleIds {
+			userRoles = append(userRoles, model.UserRole{UserID: userId, RoleID: roleId})
+		}
+
+		return tx.Create(&userRoles).Error
+	})
+
+	return translate(err, "role")
+}
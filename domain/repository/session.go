@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Insert(context.Context, *model.Session) error
+	FindById(context.Context, string) (*model.Session, error)
+	FindByUserIdAndId(context.Context, uint32, string) (*model.Session, error)
+	GetActiveByUserId(context.Context, uint32, time.Time) ([]model.Session, error)
+	Update(context.Context, *model.Session) error
+	TouchLastSeen(context.Context, string, time.Time) error
+	Revoke(context.Context, string) error
+	RevokeByUserId(context.Context, uint32) error
+}
+
+type sessionImpl struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionImpl{
+		db: db,
+	}
+}
+
+func (r *sessionImpl) Insert(ctx context.Context, session *model.Session) error {
+	err := conn(ctx, r.db).Create(session).Error
+
+	return translate(err, "session")
+}
+
+func (r *sessionImpl) FindById(ctx context.Context, id string) (*model.Session, error) {
+	var session model.Session
+	if err := conn(ctx, r.db).Model(&model.Session{}).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, translate(err, "session")
+	}
+
+	return &session, nil
+}
+
+func (r *sessionImpl) FindByUserIdAndId(ctx context.Context, userId uint32, id string) (*model.Session, error) {
+	var session model.Session
+	if err := conn(ctx, r.db).Model(&model.Session{}).Where("user_id = ? AND id = ?", userId, id).First(&session).Error; err != nil {
+		return nil, translate(err, "session")
+	}
+
+	return &session, nil
+}
+
+// GetActiveByUserId lists the sessions neither revoked nor expired at now, the most recently seen first
+func (r *sessionImpl) GetActiveByUserId(ctx context.Context, userId uint32, now time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	err := conn(ctx, r.db).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+
+	return sessions, translate(err, "session")
+}
+
+func (r *sessionImpl) Update(ctx context.Context, session *model.Session) error {
+	err := conn(ctx, r.db).Save(session).Error
+
+	return translate(err, "session")
+}
+
+// TouchLastSeen only sets the time the session was seen, the other columns and updated_at are left as they are
+func (r *sessionImpl) TouchLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	err := conn(ctx, r.db).Model(&model.Session{}).Where("id = ?", id).UpdateColumn("last_seen_at", lastSeenAt).Error
+
+	return translate(err, "session")
+}
+
+// Revoke ends the session, it returns gorm.ErrRecordNotFound when it was already revoked
+func (r *sessionImpl) Revoke(ctx context.Context, id string) error {
+	result := conn(ctx, r.db).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return translate(result.Error, "session")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "session")
+	}
+
+	return nil
+}
+
+func (r *sessionImpl) RevokeByUserId(ctx context.Context, userId uint32) error {
+	err := conn(ctx, r.db).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now()).Error
+
+	return translate(err, "session")
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+)
+
+func TestApiKeyScopesAndLastUsed(t *testing.T) {
+	db := newSqliteDb(t)
+	userRepo := repository.NewUserRepository(db)
+	apiKeyRepo := repository.NewApiKeyRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := model.ApiKey{UserID: user.ID, Name: "ci", Prefix: "gbk_abcdefgh", KeyHash: "hash", Scopes: []string{"user:read", "role:read"}}
+	assert.Equal(t, apiKeyRepo.Insert(ctx, &apiKey), nil)
+
+	// the scopes are stored as json and read back as the same list
+	stored, err := apiKeyRepo.FindByKeyHash(ctx, "hash")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.Scopes, []string{"user:read", "role:read"})
+	assert.Equal(t, stored.LastUsedAt, nil)
+
+	usedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, usedAt), nil)
+
+	stored, err = apiKeyRepo.FindByUserIdAndId(ctx, user.ID, apiKey.ID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.LastUsedAt.Equal(usedAt), true)
+
+	// another user cannot reach the key
+	_, err = apiKeyRepo.FindByUserIdAndId(ctx, user.ID+1, apiKey.ID)
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+
+	assert.Equal(t, apiKeyRepo.Delete(ctx, apiKey.ID), nil)
+	_, err = apiKeyRepo.FindByKeyHash(ctx, "hash")
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/pkg/denylist"
+)
+
+func TestDatabaseDenylist(t *testing.T) {
+	db := newSqliteDb(t)
+	tokenDenylist := denylist.NewDatabaseDenylist(db)
+	ctx := context.TODO()
+
+	issuedAt := time.Now().Add(-time.Minute)
+
+	revoked, err := tokenDenylist.IsRevoked(ctx, "jti", 1, issuedAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, false)
+
+	err = tokenDenylist.Revoke(ctx, "jti", time.Now().Add(time.Hour))
+	assert.Equal(t, err, nil)
+
+	revoked, err = tokenDenylist.IsRevoked(ctx, "jti", 1, issuedAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, true)
+
+	err = tokenDenylist.Revoke(ctx, "expired", time.Now().Add(-time.Second))
+	assert.Equal(t, err, nil)
+
+	revoked, err = tokenDenylist.IsRevoked(ctx, "expired", 1, issuedAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, false)
+
+	err = tokenDenylist.RevokeUser(ctx, 2, time.Now(), time.Now().Add(time.Hour))
+	assert.Equal(t, err, nil)
+
+	revoked, err = tokenDenylist.IsRevoked(ctx, "other", 2, issuedAt)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, true)
+
+	revoked, err = tokenDenylist.IsRevoked(ctx, "other", 2, time.Now().Add(time.Minute))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, false)
+
+	// the iat of a token is in whole seconds, one issued right after the revocation falls in the same second
+	revokedAt := time.Unix(time.Now().Unix(), int64(500*time.Millisecond))
+	err = tokenDenylist.RevokeUser(ctx, 3, revokedAt, time.Now().Add(time.Hour))
+	assert.Equal(t, err, nil)
+
+	revoked, err = tokenDenylist.IsRevoked(ctx, "other", 3, time.Unix(revokedAt.Unix(), 0))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, false)
+
+	revoked, err = tokenDenylist.IsRevoked(ctx, "other", 3, time.Unix(revokedAt.Unix()-1, 0))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, revoked, true)
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/pkg/lockout"
+)
+
+func TestDatabaseLockoutStore(t *testing.T) {
+	db := newSqliteDb(t)
+	store := lockout.NewDatabaseStore(db)
+	ctx := context.TODO()
+
+	now := time.Now()
+
+	attempt, err := store.Get(ctx, "email:user@mail.com")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempt == nil, true)
+
+	for i := 1; i <= 3; i++ {
+		attempt, err = store.Fail(ctx, "email:user@mail.com", now, now.Add(-time.Minute))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, attempt.Failures, i)
+	}
+
+	// the previous failure is older than the window, the count starts over
+	later := now.Add(2 * time.Minute)
+	attempt, err = store.Fail(ctx, "email:user@mail.com", later, later.Add(-time.Minute))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempt.Failures, 1)
+
+	err = store.Lock(ctx, "email:user@mail.com", later.Add(time.Hour))
+	assert.Equal(t, err, nil)
+
+	attempt, err = store.Get(ctx, "email:user@mail.com")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempt.LockedUntil.Unix(), later.Add(time.Hour).Unix())
+
+	err = store.Reset(ctx, "email:user@mail.com")
+	assert.Equal(t, err, nil)
+
+	attempt, err = store.Get(ctx, "email:user@mail.com")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempt == nil, true)
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+)
+
+func TestUserIdentityProviderAndSubject(t *testing.T) {
+	db := newSqliteDb(t)
+	userRepo := repository.NewUserRepository(db)
+	userIdentityRepo := repository.NewUserIdentityRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	identity := model.UserIdentity{UserID: user.ID, Provider: "corporate", Subject: "subject", Email: "user@mail.com"}
+	assert.Equal(t, userIdentityRepo.Insert(ctx, &identity), nil)
+
+	// a subject is only linked once per provider, the same subject of another provider is another identity
+	duplicate := model.UserIdentity{UserID: user.ID, Provider: "corporate", Subject: "subject"}
+	assert.NotEqual(t, userIdentityRepo.Insert(ctx, &duplicate), nil)
+
+	other := model.UserIdentity{UserID: user.ID, Provider: "other", Subject: "subject"}
+	assert.Equal(t, userIdentityRepo.Insert(ctx, &other), nil)
+
+	stored, err := userIdentityRepo.FindByProviderAndSubject(ctx, "corporate", "subject")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.ID, identity.ID)
+	assert.Equal(t, stored.UserID, user.ID)
+
+	_, err = userIdentityRepo.FindByProviderAndSubject(ctx, "corporate", "unknown")
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+}
+
+func TestOidcStateSingleUse(t *testing.T) {
+	db := newSqliteDb(t)
+	oidcStateRepo := repository.NewOidcStateRepository(db)
+	ctx := context.TODO()
+
+	oidcState := model.OidcState{StateHash: "hash", Provider: "corporate", Nonce: "nonce", CodeVerifier: "verifier", ExpiresAt: time.Now().Add(time.Minute)}
+	assert.Equal(t, oidcStateRepo.Insert(ctx, &oidcState), nil)
+
+	stored, err := oidcStateRepo.FindByStateHash(ctx, "hash")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.CodeVerifier, "verifier")
+
+	assert.Equal(t, oidcStateRepo.Delete(ctx, stored.ID), nil)
+	assert.Equal(t, errors.Is(oidcStateRepo.Delete(ctx, stored.ID), custErr.ErrNotFound), true)
+
+	_, err = oidcStateRepo.FindByStateHash(ctx, "hash")
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+)
+
+func TestRefreshTokenRotate(t *testing.T) {
+	db := newSqliteDb(t)
+	userRepo := repository.NewUserRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, jti := range []string{"first", "second"} {
+		if err := refreshTokenRepo.Insert(ctx, &model.RefreshToken{
+			Jti:       jti,
+			FamilyID:  "family",
+			UserID:    user.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := refreshTokenRepo.Rotate(ctx, "first", "second")
+	assert.Equal(t, err, nil)
+
+	rotated, err := refreshTokenRepo.FindByJti(ctx, "first")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *rotated.ReplacedBy, "second")
+	assert.NotEqual(t, rotated.RevokedAt, nil)
+
+	err = refreshTokenRepo.Rotate(ctx, "first", "third")
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+
+	err = refreshTokenRepo.RevokeFamily(ctx, "family")
+	assert.Equal(t, err, nil)
+
+	revoked, err := refreshTokenRepo.FindByJti(ctx, "second")
+	assert.Equal(t, err, nil)
+	assert.NotEqual(t, revoked.RevokedAt, nil)
+}
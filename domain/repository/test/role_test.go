@@ -0,0 +1,41 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+)
+
+func TestRoleSetUserRoles(t *testing.T) {
+	db := newSqliteDb(t)
+	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	roles, err := roleRepo.GetByNames(ctx, []string{"member"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(roles), 1)
+
+	err = roleRepo.SetUserRoles(ctx, user.ID, []uint32{roles[0].ID})
+	assert.Equal(t, err, nil)
+
+	userRoles, err := roleRepo.GetByUserId(ctx, user.ID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, model.RoleNames(userRoles), []string{"member"})
+	assert.Equal(t, model.PermissionNames(userRoles), []string{"user:read"})
+
+	err = roleRepo.SetUserRoles(ctx, user.ID, nil)
+	assert.Equal(t, err, nil)
+
+	userRoles, err = roleRepo.GetByUserId(ctx, user.ID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(userRoles), 0)
+}
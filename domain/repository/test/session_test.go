@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+)
+
+func TestSessionActiveAndRevoke(t *testing.T) {
+	db := newSqliteDb(t)
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sessions := []model.Session{
+		{ID: "older", UserID: user.ID, UserAgent: "agent", IpAddress: "10.0.0.1", LastSeenAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "newer", UserID: user.ID, LastSeenAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", UserID: user.ID, LastSeenAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(-time.Minute)},
+	}
+	for i := range sessions {
+		assert.Equal(t, sessionRepo.Insert(ctx, &sessions[i]), nil)
+	}
+
+	// the expired session is left out and the most recently seen comes first
+	active, err := sessionRepo.GetActiveByUserId(ctx, user.ID, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(active), 2)
+	assert.Equal(t, active[0].ID, "newer")
+	assert.Equal(t, active[1].UserAgent, "agent")
+
+	seenAt := now.Add(time.Minute)
+	assert.Equal(t, sessionRepo.TouchLastSeen(ctx, "older", seenAt), nil)
+
+	stored, err := sessionRepo.FindById(ctx, "older")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.LastSeenAt.Equal(seenAt), true)
+
+	// another user cannot reach the session
+	_, err = sessionRepo.FindByUserIdAndId(ctx, user.ID+1, "older")
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+
+	// a session is only revoked once
+	assert.Equal(t, sessionRepo.Revoke(ctx, "older"), nil)
+	assert.Equal(t, errors.Is(sessionRepo.Revoke(ctx, "older"), custErr.ErrNotFound), true)
+
+	active, err = sessionRepo.GetActiveByUserId(ctx, user.ID, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(active), 1)
+
+	assert.Equal(t, sessionRepo.RevokeByUserId(ctx, user.ID), nil)
+	active, err = sessionRepo.GetActiveByUserId(ctx, user.ID, now)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(active), 0)
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+)
+
+func TestTxManager(t *testing.T) {
+	db := newSqliteDb(t)
+	txManager := repository.NewTxManager(db)
+	userRepo := repository.NewUserRepository(db)
+	ctx := context.TODO()
+
+	countUsers := func() int64 {
+		var count int64
+		if err := db.Model(&model.User{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	insertUser := func(ctx context.Context, email string) error {
+		return userRepo.Insert(ctx, &model.User{Name: email, Email: email, Password: "secret"})
+	}
+
+	// committed when the function succeeds
+	err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return insertUser(ctx, "commit@mail.com")
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, countUsers(), int64(1))
+
+	// rolled back when the function fails
+	failed := errors.New("failed")
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := insertUser(ctx, "rollback@mail.com"); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.Equal(t, err, failed)
+	assert.Equal(t, countUsers(), int64(1))
+
+	// rolled back when the function panics, the panic is passed on
+	func() {
+		defer func() {
+			assert.Equal(t, recover(), "boom")
+		}()
+
+		_ = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := insertUser(ctx, "panic@mail.com"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+	assert.Equal(t, countUsers(), int64(1))
+
+	// a failed nested call only rolls back to its savepoint
+	err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := insertUser(ctx, "outer@mail.com"); err != nil {
+			return err
+		}
+
+		nestedErr := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := insertUser(ctx, "nested@mail.com"); err != nil {
+				return err
+			}
+			return failed
+		})
+		assert.Equal(t, nestedErr, failed)
+
+		return nil
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, countUsers(), int64(2))
+
+	_, err = userRepo.FindByEmail(ctx, "nested@mail.com")
+	assert.NotEqual(t, err, nil)
+}
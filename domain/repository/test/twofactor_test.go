@@ -0,0 +1,61 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+)
+
+func TestTwoFactorSaveAndUse(t *testing.T) {
+	db := newSqliteDb(t)
+	userRepo := repository.NewUserRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first save inserts, the next ones update the same row
+	twoFactor := model.TwoFactor{UserID: user.ID, Secret: "FIRST"}
+	assert.Equal(t, twoFactorRepo.Save(ctx, &twoFactor), nil)
+
+	twoFactor.Secret = "SECOND"
+	assert.Equal(t, twoFactorRepo.Save(ctx, &twoFactor), nil)
+
+	stored, err := twoFactorRepo.FindByUserId(ctx, user.ID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.Secret, "SECOND")
+
+	assert.Equal(t, twoFactorRepo.UseStep(ctx, user.ID, 10), nil)
+	assert.Equal(t, errors.Is(twoFactorRepo.UseStep(ctx, user.ID, 10), custErr.ErrNotFound), true)
+	assert.Equal(t, errors.Is(twoFactorRepo.UseStep(ctx, user.ID, 9), custErr.ErrNotFound), true)
+	assert.Equal(t, twoFactorRepo.UseStep(ctx, user.ID, 11), nil)
+
+	err = twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, []model.RecoveryCode{
+		{UserID: user.ID, CodeHash: "first"},
+		{UserID: user.ID, CodeHash: "second"},
+	})
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, twoFactorRepo.UseRecoveryCode(ctx, user.ID, "first"), nil)
+	assert.Equal(t, errors.Is(twoFactorRepo.UseRecoveryCode(ctx, user.ID, "first"), custErr.ErrNotFound), true)
+	assert.Equal(t, errors.Is(twoFactorRepo.UseRecoveryCode(ctx, user.ID+1, "second"), custErr.ErrNotFound), true)
+
+	// new codes replace the previous ones
+	err = twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, []model.RecoveryCode{{UserID: user.ID, CodeHash: "third"}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, errors.Is(twoFactorRepo.UseRecoveryCode(ctx, user.ID, "second"), custErr.ErrNotFound), true)
+
+	assert.Equal(t, twoFactorRepo.Delete(ctx, user.ID), nil)
+	assert.Equal(t, errors.Is(twoFactorRepo.UseRecoveryCode(ctx, user.ID, "third"), custErr.ErrNotFound), true)
+
+	_, err = twoFactorRepo.FindByUserId(ctx, user.ID)
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	pkgGorm "github.com/si-bas/go-rest-boilerplate/pkg/gorm"
+	"github.com/si-bas/go-rest-boilerplate/pkg/migration"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"gorm.io/gorm"
+)
+
+func newSqliteDb(t *testing.T) *gorm.DB {
+	config.Config = &config.Cfg{}
+	config.Config.Db = config.DB{
+		Driver: pkgGorm.DriverSqlite,
+		Name:   fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()),
+		// the in-memory database is dropped once its last connection is closed
+		Connection: config.DbConnConfig{Open: 1, Idle: 1},
+	}
+
+	db := pkgGorm.ConnectDB()
+	if err := migration.Run("up", db, pkgGorm.DriverSqlite); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pkgGorm.CloseDB(db) })
+
+	return db
+}
+
+func TestUserKeywordSearch(t *testing.T) {
+	db := newSqliteDb(t)
+	repo := repository.NewUserRepository(db)
+	ctx := context.TODO()
+
+	for _, user := range []model.User{
+		{Name: "John Doe", Email: "john@mail.com", Password: "secret"},
+		{Name: "Jane", Email: "JANE@MAIL.COM", Password: "secret"},
+	} {
+		user := user
+		if err := repo.Insert(ctx, &user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		keyword string
+		want    int
+	}{
+		{name: "match name ignoring case", keyword: "JOHN", want: 1},
+		{name: "match email ignoring case", keyword: "jane@mail", want: 1},
+		{name: "match both", keyword: "MAIL.com", want: 2},
+		{name: "no match", keyword: "nobody", want: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			users, err := repo.GetFiltered(ctx, model.UserFilter{Keyword: tc.keyword})
+
+			assert.Equal(t, err, nil)
+			assert.Equal(t, len(users), tc.want)
+		})
+	}
+}
+
+func TestUserCancelledContext(t *testing.T) {
+	db := newSqliteDb(t)
+	repo := repository.NewUserRepository(db)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	// the query is bound to the request context and not sent once it is done
+	_, err := repo.GetFiltered(ctx, model.UserFilter{Keyword: "john"})
+	assert.Equal(t, errors.Is(err, context.Canceled), true)
+
+	_, err = repo.FindById(repository.WithPrimary(ctx), 1)
+	assert.Equal(t, errors.Is(err, context.Canceled), true)
+}
+
+func TestUserDuplicateEmail(t *testing.T) {
+	db := newSqliteDb(t)
+	repo := repository.NewUserRepository(db)
+	ctx := context.TODO()
+
+	first := model.User{Name: "first", Email: "user@mail.com", Password: "secret"}
+	if err := repo.Insert(ctx, &first); err != nil {
+		t.Fatal(err)
+	}
+
+	// the unique constraint decides, there is no check before the insert
+	second := model.User{Name: "second", Email: "user@mail.com", Password: "secret"}
+	err := repo.Insert(ctx, &second)
+	assert.Equal(t, errors.Is(err, custErr.ErrConflict), true)
+
+	first.Email = "other@mail.com"
+	if err := repo.Update(ctx, &first); err != nil {
+		t.Fatal(err)
+	}
+
+	second.Email = "second@mail.com"
+	if err := repo.Insert(ctx, &second); err != nil {
+		t.Fatal(err)
+	}
+
+	second.Email = first.Email
+	err = repo.Update(ctx, &second)
+	assert.Equal(t, errors.Is(err, custErr.ErrConflict), true)
+
+	// the driver error is kept as the cause
+	_, err = repo.FindById(ctx, 999)
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+	assert.Equal(t, errors.Is(err, gorm.ErrRecordNotFound), true)
+	assert.Equal(t, custErr.Message(err), "user not found")
+}
+
+func TestUserSoftDelete(t *testing.T) {
+	db := newSqliteDb(t)
+	repo := repository.NewUserRepository(db)
+	ctx := context.TODO()
+
+	user := model.User{Name: "user", Email: "user@mail.com", Password: "secret"}
+	if err := repo.Insert(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := repo.FindById(ctx, user.ID)
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+
+	_, err = repo.FindByEmail(ctx, user.Email)
+	assert.Equal(t, errors.Is(err, custErr.ErrNotFound), true)
+
+	users, err := repo.GetFiltered(ctx, model.UserFilter{Keyword: "user"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(users), 0)
+
+	users, err = repo.GetFiltered(ctx, model.UserFilter{Keyword: "user", IncludeDeleted: true})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(users), 1)
+
+	if err := repo.Restore(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := repo.FindById(ctx, user.ID)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, restored.Email, user.Email)
+}
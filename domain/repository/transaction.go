@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type TxManager interface {
+	WithinTransaction(context.Context, func(context.Context) error) error
+}
+
+type txManagerImpl struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManagerImpl{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, the repositories called with the ctx given to fn use it.
+// The transaction is committed when fn returns nil and rolled back when it returns an error or panics,
+// a call nested in another transaction only rolls back to its own savepoint.
+func (m *txManagerImpl) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	db := m.db
+	if tx, ok := ctx.Value(txKey).(*gorm.DB); ok {
+		db = tx
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey, tx))
+	})
+}
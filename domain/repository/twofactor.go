@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	FindByUserId(context.Context, uint32) (*model.TwoFactor, error)
+	Save(context.Context, *model.TwoFactor) error
+	UseStep(context.Context, uint32, int64) error
+	Delete(context.Context, uint32) error
+	ReplaceRecoveryCodes(context.Context, uint32, []model.RecoveryCode) error
+	UseRecoveryCode(context.Context, uint32, string) error
+}
+
+type twoFactorImpl struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorImpl{
+		db: db,
+	}
+}
+
+func (r *twoFactorImpl) FindByUserId(ctx context.Context, userId uint32) (*model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+	if err := conn(ctx, r.db).Model(&model.TwoFactor{}).Where("user_id = ?", userId).First(&twoFactor).Error; err != nil {
+		return nil, translate(err, "two-factor authentication")
+	}
+
+	return &twoFactor, nil
+}
+
+func (r *twoFactorImpl) Save(ctx context.Context, twoFactor *model.TwoFactor) error {
+	err := conn(ctx, r.db).Save(twoFactor).Error
+
+	return translate(err, "two-factor authentication")
+}
+
+// UseStep records the step of a valid code, it returns gorm.ErrRecordNotFound when the step or a later one
+// was already used, e.g. the same code was sent twice
+func (r *twoFactorImpl) UseStep(ctx context.Context, userId uint32, step int64) error {
+	result := conn(ctx, r.db).Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userId, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return translate(result.Error, "two-factor authentication")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "two-factor authentication")
+	}
+
+	return nil
+}
+
+// Delete removes the secret and the recovery codes of the user
+func (r *twoFactorImpl) Delete(ctx context.Context, userId uint32) error {
+	if err := conn(ctx, r.db).Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return translate(err, "two-factor authentication")
+	}
+
+	err := conn(ctx, r.db).Where("user_id = ?", userId).Delete(&model.TwoFactor{}).Error
+
+	return translate(err, "two-factor authentication")
+}
+
+func (r *twoFactorImpl) ReplaceRecoveryCodes(ctx context.Context, userId uint32, recoveryCodes []model.RecoveryCode) error {
+	if err := conn(ctx, r.db).Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return translate(err, "two-factor authentication")
+	}
+
+	if len(recoveryCodes) == 0 {
+		return nil
+	}
+
+	err := conn(ctx, r.db).Create(&recoveryCodes).Error
+
+	return translate(err, "two-factor authentication")
+}
+
+// UseRecoveryCode consumes the code, it returns gorm.ErrRecordNotFound when the user has no such unused code
+func (r *twoFactorImpl) UseRecoveryCode(ctx context.Context, userId uint32, codeHash string) error {
+	result := conn(ctx, r.db).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return translate(result.Error, "two-factor authentication")
+	}
+
+	if result.RowsAffected == 0 {
+		return translate(gorm.ErrRecordNotFound, "two-factor authentication")
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
 	"github.com/si-bas/go-rest-boilerplate/shared/helper/pagination"
@@ -9,7 +10,7 @@ import (
 )
 
 type UserRepository interface {
-	FilteredDb(model.UserFilter) *gorm.DB
+	FilteredDb(context.Context, model.UserFilter) *gorm.DB
 
 	Insert(context.Context, *model.User) error
 	GetPaginate(context.Context, model.UserFilter, pagination.Param) ([]model.User, *pagination.Param, error)
@@ -17,6 +18,10 @@ type UserRepository interface {
 	CountByEmail(context.Context, string) (*int64, error)
 	FindById(context.Context, uint32) (*model.User, error)
 	FindByEmail(context.Context, string) (*model.User, error)
+	FindByIdWithDeleted(context.Context, uint32) (*model.User, error)
+	Update(context.Context, *model.User) error
+	Delete(context.Context, uint32) error
+	Restore(context.Context, uint32) error
 }
 
 type userImpl struct {
@@ -30,23 +35,25 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 func (r *userImpl) Insert(ctx context.Context, user *model.User) error {
-	return r.db.Create(user).Error
+	err := conn(ctx, r.db).Create(user).Error
+
+	return translate(err, "user")
 }
 
 func (r *userImpl) GetFiltered(ctx context.Context, filter model.UserFilter) ([]model.User, error) {
 	var users []model.User
-	err := r.FilteredDb(filter).Find(&users).Error
+	err := r.FilteredDb(ctx, filter).Find(&users).Error
 
-	return users, err
+	return users, translate(err, "user")
 }
 
 func (r *userImpl) GetPaginate(ctx context.Context, filter model.UserFilter, param pagination.Param) ([]model.User, *pagination.Param, error) {
 	var users []model.User
 
-	filteredDb := r.FilteredDb(filter)
+	filteredDb := r.FilteredDb(ctx, filter)
 
 	if err := filteredDb.Scopes(pagination.Paginate(model.User{}, &param, filteredDb)).Find(&users).Error; err != nil {
-		return nil, nil, err
+		return nil, nil, translate(err, "user")
 	}
 
 	return users, &param, nil
@@ -54,19 +61,25 @@ func (r *userImpl) GetPaginate(ctx context.Context, filter model.UserFilter, par
 
 func (r *userImpl) CountByEmail(ctx context.Context, email string) (*int64, error) {
 	var count int64
-	if err := r.db.Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
-		return nil, err
+	// deleted users still hold their email in the unique index
+	if err := conn(ctx, r.db).Unscoped().Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return nil, translate(err, "user")
 	}
 
 	return &count, nil
 }
 
-func (r *userImpl) FilteredDb(filter model.UserFilter) *gorm.DB {
-	chain := r.db.Model(&model.User{})
+func (r *userImpl) FilteredDb(ctx context.Context, filter model.UserFilter) *gorm.DB {
+	chain := conn(ctx, r.db).Model(&model.User{})
+
+	if filter.IncludeDeleted {
+		chain = chain.Unscoped()
+	}
 
 	if filter.Keyword != "" {
-		searchVal := "%" + filter.Keyword + "%"
-		chain.Where("name LIKE ? OR email LIKE ?", searchVal, searchVal)
+		// LIKE is case sensitive on postgres, lower both sides to match mysql and sqlite
+		searchVal := "%" + strings.ToLower(filter.Keyword) + "%"
+		chain.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", searchVal, searchVal)
 	}
 
 	return chain
@@ -74,17 +87,45 @@ func (r *userImpl) FilteredDb(filter model.UserFilter) *gorm.DB {
 
 func (r *userImpl) FindById(ctx context.Context, id uint32) (*model.User, error) {
 	var user model.User
-	if err := r.db.Model(&model.User{}).Where("id = ?", id).First(&user).Error; err != nil {
-		return nil, err
+	if err := conn(ctx, r.db).Model(&model.User{}).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translate(err, "user")
+	}
+
+	return &user, nil
+}
+
+func (r *userImpl) FindByIdWithDeleted(ctx context.Context, id uint32) (*model.User, error) {
+	var user model.User
+	if err := conn(ctx, r.db).Unscoped().Model(&model.User{}).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translate(err, "user")
 	}
 
 	return &user, nil
 }
+
 func (r *userImpl) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	if err := r.db.Model(&model.User{}).Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
+	if err := conn(ctx, r.db).Model(&model.User{}).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translate(err, "user")
 	}
 
 	return &user, nil
 }
+
+func (r *userImpl) Update(ctx context.Context, user *model.User) error {
+	err := conn(ctx, r.db).Save(user).Error
+
+	return translate(err, "user")
+}
+
+func (r *userImpl) Delete(ctx context.Context, id uint32) error {
+	err := conn(ctx, r.db).Delete(&model.User{}, id).Error
+
+	return translate(err, "user")
+}
+
+func (r *userImpl) Restore(ctx context.Context, id uint32) error {
+	err := conn(ctx, r.db).Unscoped().Model(&model.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+
+	return translate(err, "user")
+}
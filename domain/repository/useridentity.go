@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	Insert(context.Context, *model.UserIdentity) error
+	FindByProviderAndSubject(context.Context, string, string) (*model.UserIdentity, error)
+}
+
+type userIdentityImpl struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityImpl{
+		db: db,
+	}
+}
+
+func (r *userIdentityImpl) Insert(ctx context.Context, userIdentity *model.UserIdentity) error {
+	err := conn(ctx, r.db).Create(userIdentity).Error
+
+	return translate(err, "identity")
+}
+
+func (r *userIdentityImpl) FindByProviderAndSubject(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+	var userIdentity model.UserIdentity
+	if err := conn(ctx, r.db).Model(&model.UserIdentity{}).Where("provider = ? AND subject = ?", provider, subject).First(&userIdentity).Error; err != nil {
+		return nil, translate(err, "identity")
+	}
+
+	return &userIdentity, nil
+}
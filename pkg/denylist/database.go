@@ -0,0 +1,61 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type databaseDenylist struct {
+	db *gorm.DB
+}
+
+func NewDatabaseDenylist(db *gorm.DB) TokenDenylist {
+	return &databaseDenylist{
+		db: db,
+	}
+}
+
+func (d *databaseDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	return d.insert(ctx, &model.DeniedToken{
+		Jti:       &jti,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (d *databaseDenylist) RevokeUser(ctx context.Context, userId uint32, revokedAt time.Time, expiresAt time.Time) error {
+	return d.insert(ctx, &model.DeniedToken{
+		UserID:    &userId,
+		RevokedAt: revokedAt.Truncate(time.Second),
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (d *databaseDenylist) IsRevoked(ctx context.Context, jti string, userId uint32, issuedAt time.Time) (bool, error) {
+	var count int64
+
+	// read from the primary, a lagging replica would let a just revoked token through
+	err := d.db.WithContext(ctx).Clauses(dbresolver.Write).Model(&model.DeniedToken{}).
+		Where("expires_at > ?", time.Now()).
+		Where(d.db.Where("jti = ?", jti).Or("user_id = ? AND revoked_at > ?", userId, issuedAt)).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (d *databaseDenylist) insert(ctx context.Context, deniedToken *model.DeniedToken) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&model.DeniedToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(deniedToken).Error
+	})
+}
@@ -0,0 +1,37 @@
+package denylist
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DriverMemory   = "memory"
+	DriverDatabase = "database"
+)
+
+// TokenDenylist keeps revoked access tokens until they would have expired anyway
+type TokenDenylist interface {
+	// Revoke denies the token with the jti
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUser denies every token of the user issued before revokedAt. It is kept in whole seconds like the iat
+	// of the tokens, a token issued in the same second as the revocation is not denied.
+	RevokeUser(ctx context.Context, userId uint32, revokedAt time.Time, expiresAt time.Time) error
+	// IsRevoked checks the token by its jti, its user and the time it was issued
+	IsRevoked(ctx context.Context, jti string, userId uint32, issuedAt time.Time) (bool, error)
+}
+
+// New to instantiate the denylist of the driver, memory when it is not set.
+// The memory denylist is local to the process, use database when running several replicas.
+func New(driver string, db *gorm.DB) TokenDenylist {
+	switch driver {
+	case "", DriverMemory:
+		return NewMemoryDenylist()
+	case DriverDatabase:
+		return NewDatabaseDenylist(db)
+	default:
+		panic("error unknown token denylist driver " + driver)
+	}
+}
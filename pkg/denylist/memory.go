@@ -0,0 +1,95 @@
+package denylist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+type memoryDenylist struct {
+	mu        sync.RWMutex
+	tokens    map[string]time.Time
+	users     map[uint32]userRevocation
+	lastSweep time.Time
+}
+
+func NewMemoryDenylist() TokenDenylist {
+	return &memoryDenylist{
+		tokens:    map[string]time.Time{},
+		users:     map[uint32]userRevocation{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (d *memoryDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tokens[jti] = expiresAt
+	d.sweep()
+
+	return nil
+}
+
+func (d *memoryDenylist) RevokeUser(ctx context.Context, userId uint32, revokedAt time.Time, expiresAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	revokedAt = revokedAt.Truncate(time.Second)
+	if current, ok := d.users[userId]; ok {
+		if current.revokedAt.After(revokedAt) {
+			revokedAt = current.revokedAt
+		}
+		if current.expiresAt.After(expiresAt) {
+			expiresAt = current.expiresAt
+		}
+	}
+	d.users[userId] = userRevocation{revokedAt: revokedAt, expiresAt: expiresAt}
+	d.sweep()
+
+	return nil
+}
+
+func (d *memoryDenylist) IsRevoked(ctx context.Context, jti string, userId uint32, issuedAt time.Time) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now()
+	if expiresAt, ok := d.tokens[jti]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+
+	if revocation, ok := d.users[userId]; ok && now.Before(revocation.expiresAt) && issuedAt.Before(revocation.revokedAt) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// sweep drops expired entries, at most once per sweepInterval, the caller must hold the lock
+func (d *memoryDenylist) sweep() {
+	now := time.Now()
+	if now.Sub(d.lastSweep) < sweepInterval {
+		return
+	}
+	d.lastSweep = now
+
+	for jti, expiresAt := range d.tokens {
+		if !now.Before(expiresAt) {
+			delete(d.tokens, jti)
+		}
+	}
+
+	for userId, revocation := range d.users {
+		if !now.Before(revocation.expiresAt) {
+			delete(d.users, userId)
+		}
+	}
+}
@@ -2,10 +2,10 @@ package gorm
 
 import (
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/si-bas/go-rest-boilerplate/config"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/plugin/dbresolver"
@@ -14,43 +14,92 @@ import (
 func ConnectDB() *gorm.DB {
 
 	// Write
-	db := connectMysqlDb(config.Config.Db)
+	db := connectDb(config.Config.Db)
 
 	// Read
-	db.Use(dbresolver.Register(dbresolver.Config{
-		Replicas: []gorm.Dialector{mysql.Open(configToDsn(config.Config.Db))},
-		Policy:   dbresolver.RandomPolicy{},
-	}))
+	if len(config.Config.Db.Replicas) == 0 {
+		return db
+	}
+
+	var replicas []gorm.Dialector
+	for _, replicaConfig := range config.Config.Db.Replicas {
+		replicaConfig.Driver = Driver(config.Config.Db)
+		replicas = append(replicas, connectReplica(replicaConfig.DB))
+	}
+
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   replicaPolicy(config.Config.Db),
+	})); err != nil {
+		panic("error registering database replicas, err=" + err.Error())
+	}
 
 	return db
 }
 
-func connectMysqlDb(dbConfig config.DB) *gorm.DB {
+// ConnectPrimaryDB connects to the primary only, for tasks that never read from replicas
+func ConnectPrimaryDB() *gorm.DB {
+	return connectDb(config.Config.Db)
+}
+
+// CloseDB closes the primary and every replica connection pool
+func CloseDB(db *gorm.DB) error {
+	var errs []error
+
+	if resolver, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()].(*dbresolver.DBResolver); ok {
+		_ = resolver.Call(func(connPool gorm.ConnPool) error {
+			if conn, ok := connPool.(io.Closer); ok {
+				if err := conn.Close(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	sqlDb, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDb.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close database connections: %v", errs)
+	}
+
+	return nil
+}
+
+// connectReplica opens the replica with its own pool settings,
+// the resolver then reuses that pool instead of opening a new one
+func connectReplica(dbConfig config.DB) gorm.Dialector {
+	replicaDb, _ := connectDb(dbConfig).DB()
+
+	return connDialector(Driver(dbConfig), replicaDb)
+}
 
-	dsn := configToDsn(dbConfig)
+func connectDb(dbConfig config.DB) *gorm.DB {
 
 	logLevel := logger.Silent
 	if config.Config.App.Debug {
 		logLevel = logger.Info
 	}
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector(dbConfig), &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
 	})
 	if err != nil {
 		panic("error connecting to database, err=" + err.Error())
 	}
 
-	mysqlDb, _ := db.DB()
-	mysqlDb.SetMaxOpenConns(dbConfig.Connection.Open)
-	mysqlDb.SetMaxIdleConns(dbConfig.Connection.Idle)
+	sqlDb, _ := db.DB()
+	sqlDb.SetMaxOpenConns(dbConfig.Connection.Open)
+	sqlDb.SetMaxIdleConns(dbConfig.Connection.Idle)
 
 	mili, _ := time.ParseDuration(fmt.Sprintf("%dms", dbConfig.Connection.TTL))
-	mysqlDb.SetConnMaxLifetime(time.Duration(mili.Nanoseconds()))
+	sqlDb.SetConnMaxLifetime(time.Duration(mili.Nanoseconds()))
 
 	return db
 }
-
-func configToDsn(dbConfig config.DB) string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=Local", dbConfig.Username, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.Name)
-}
@@ -0,0 +1,91 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+// Driver to get the configured driver, mysql when it is not set
+func Driver(dbConfig config.DB) string {
+	if dbConfig.Driver == "" {
+		return DriverMysql
+	}
+
+	return dbConfig.Driver
+}
+
+// dialector to open a new connection pool from the config
+func dialector(dbConfig config.DB) gorm.Dialector {
+	dsn := configToDsn(dbConfig)
+
+	switch Driver(dbConfig) {
+	case DriverMysql:
+		return mysql.Open(dsn)
+	case DriverPostgres:
+		return postgres.Open(dsn)
+	case DriverSqlite:
+		return sqlite.Open(dsn)
+	default:
+		panic("error unknown database driver " + dbConfig.Driver)
+	}
+}
+
+// connDialector to reuse an already opened connection pool
+func connDialector(driver string, conn gorm.ConnPool) gorm.Dialector {
+	switch driver {
+	case DriverMysql:
+		return mysql.New(mysql.Config{Conn: conn})
+	case DriverPostgres:
+		return postgres.New(postgres.Config{Conn: conn})
+	case DriverSqlite:
+		return &sqlite.Dialector{DriverName: sqlite.DriverName, Conn: conn}
+	default:
+		panic("error unknown database driver " + driver)
+	}
+}
+
+func configToDsn(dbConfig config.DB) string {
+	switch Driver(dbConfig) {
+	case DriverPostgres:
+		return postgresDsn(dbConfig)
+	case DriverSqlite:
+		return sqliteDsn(dbConfig)
+	default:
+		return mysqlDsn(dbConfig)
+	}
+}
+
+func mysqlDsn(dbConfig config.DB) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=Local", dbConfig.Username, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.Name)
+}
+
+func postgresDsn(dbConfig config.DB) string {
+	sslMode := dbConfig.SslMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", dbConfig.Host, dbConfig.Port, dbConfig.Username, dbConfig.Password, dbConfig.Name, sslMode)
+}
+
+// sqliteDsn uses the name as database file, e.g. appdb.sqlite or file::memory:?cache=shared
+func sqliteDsn(dbConfig config.DB) string {
+	separator := "?"
+	if strings.Contains(dbConfig.Name, "?") {
+		separator = "&"
+	}
+
+	return dbConfig.Name + separator + "_foreign_keys=on"
+}
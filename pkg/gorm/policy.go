@@ -0,0 +1,72 @@
+package gorm
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	PolicyRandom     = "random"
+	PolicyRoundRobin = "round-robin"
+	PolicyWeighted   = "weighted"
+)
+
+func replicaPolicy(dbConfig config.DB) dbresolver.Policy {
+	switch dbConfig.Policy {
+	case "", PolicyRandom:
+		return dbresolver.RandomPolicy{}
+	case PolicyRoundRobin:
+		return &roundRobinPolicy{}
+	case PolicyWeighted:
+		return newWeightedPolicy(dbConfig.Replicas)
+	default:
+		panic("error unknown database replica policy " + dbConfig.Policy)
+	}
+}
+
+// roundRobinPolicy picks replicas one after another
+type roundRobinPolicy struct {
+	next uint64
+}
+
+func (p *roundRobinPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	n := atomic.AddUint64(&p.next, 1) - 1
+	return connPools[n%uint64(len(connPools))]
+}
+
+// weightedPolicy picks replicas randomly in proportion to their weight,
+// weights follow the order of the replicas in the config
+type weightedPolicy struct {
+	weights []int
+	total   int
+}
+
+func newWeightedPolicy(replicas []config.DBReplica) *weightedPolicy {
+	p := &weightedPolicy{}
+	for _, replica := range replicas {
+		weight := replica.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		p.weights = append(p.weights, weight)
+		p.total += weight
+	}
+
+	return p
+}
+
+func (p *weightedPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	n := rand.Intn(p.total)
+	for i, weight := range p.weights {
+		if n < weight && i < len(connPools) {
+			return connPools[i]
+		}
+		n -= weight
+	}
+
+	return connPools[len(connPools)-1]
+}
@@ -0,0 +1,188 @@
+package keyset
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/si-bas/go-rest-boilerplate/config"
+)
+
+// SecretKeyID is the kid of the key built from config.Jwt.Secret
+const SecretKeyID = "secret"
+
+var (
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrUnexpectedAlgorithm  = errors.New("unexpected signing method")
+	ErrSigningKeyNotDefined = errors.New("signing key is not defined")
+)
+
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+// KeySet signs tokens with one key and verifies them with every configured key,
+// keeping the previous keys configured lets the tokens they signed validate during a rotation
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// New to load the keys from the config, the secret is an HS256 key and every configured key is read from its PEM files
+func New(jwtConfig config.Jwt) (*KeySet, error) {
+	keySet := &KeySet{
+		keys: map[string]*Key{},
+	}
+
+	if jwtConfig.Secret != "" {
+		keySet.keys[SecretKeyID] = &Key{
+			ID:         SecretKeyID,
+			Method:     jwt.SigningMethodHS256,
+			PrivateKey: []byte(jwtConfig.Secret),
+			PublicKey:  []byte(jwtConfig.Secret),
+		}
+	}
+
+	for _, keyConfig := range jwtConfig.Keys {
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %s", keyConfig.ID, err.Error())
+		}
+
+		if _, ok := keySet.keys[key.ID]; ok {
+			return nil, fmt.Errorf("key %s: duplicate key id", key.ID)
+		}
+		keySet.keys[key.ID] = key
+	}
+
+	signingKeyId := jwtConfig.SigningKey
+	if signingKeyId == "" {
+		signingKeyId = SecretKeyID
+	}
+
+	signing, ok := keySet.keys[signingKeyId]
+	if !ok || signing.PrivateKey == nil {
+		return nil, ErrSigningKeyNotDefined
+	}
+	keySet.signing = signing
+
+	return keySet, nil
+}
+
+// Sign to sign the claims with the signing key, the kid header tells the verifier which key to use
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.Method, claims)
+	token.Header["kid"] = k.signing.ID
+
+	return token.SignedString(k.signing.PrivateKey)
+}
+
+// Keyfunc to look up the verification key of the token by its kid,
+// tokens signed before kid was introduced have none and fall back to the secret
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = SecretKeyID
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	// the algorithm must be the one of the key, otherwise a public key could be used as an HMAC secret
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnexpectedAlgorithm
+	}
+
+	return key.PublicKey, nil
+}
+
+func loadKey(keyConfig config.JwtKey) (*Key, error) {
+	if keyConfig.ID == "" {
+		return nil, errors.New("id is required")
+	}
+
+	method := jwt.GetSigningMethod(keyConfig.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported algorithm %s", keyConfig.Algorithm)
+	}
+
+	key := &Key{
+		ID:     keyConfig.ID,
+		Method: method,
+	}
+
+	switch {
+	case keyConfig.PrivateKey != "":
+		pem, err := os.ReadFile(keyConfig.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err := parsePrivateKey(method, pem)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+		key.PublicKey = privateKey.Public()
+	case keyConfig.PublicKey != "":
+		pem, err := os.ReadFile(keyConfig.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, err := parsePublicKey(method, pem)
+		if err != nil {
+			return nil, err
+		}
+		key.PublicKey = publicKey
+	default:
+		return nil, errors.New("either privatekey or publickey is required")
+	}
+
+	if ecdsaMethod, ok := method.(*jwt.SigningMethodECDSA); ok {
+		if key.PublicKey.(*ecdsa.PublicKey).Curve.Params().BitSize != ecdsaMethod.CurveBits {
+			return nil, fmt.Errorf("curve does not match algorithm %s", method.Alg())
+		}
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(method jwt.SigningMethod, pem []byte) (crypto.Signer, error) {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return jwt.ParseRSAPrivateKeyFromPEM(pem)
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPrivateKeyFromPEM(pem)
+	case *jwt.SigningMethodEd25519:
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey.(ed25519.PrivateKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", method.Alg())
+	}
+}
+
+func parsePublicKey(method jwt.SigningMethod, pem []byte) (crypto.PublicKey, error) {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return jwt.ParseRSAPublicKeyFromPEM(pem)
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPublicKeyFromPEM(pem)
+	case *jwt.SigningMethodEd25519:
+		return jwt.ParseEdPublicKeyFromPEM(pem)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %s", method.Alg())
+	}
+}
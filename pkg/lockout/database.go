@@ -0,0 +1,69 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type databaseStore struct {
+	db *gorm.DB
+}
+
+func NewDatabaseStore(db *gorm.DB) Store {
+	return &databaseStore{
+		db: db,
+	}
+}
+
+func (s *databaseStore) Get(ctx context.Context, identifier string) (*model.LoginAttempt, error) {
+	var attempts []model.LoginAttempt
+
+	// read from the primary, a lagging replica would hide the latest failures
+	err := s.db.WithContext(ctx).Clauses(dbresolver.Write).
+		Where("identifier = ?", identifier).Limit(1).Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+
+	return &attempts[0], nil
+}
+
+// Fail increments the count in a single upsert so concurrent failures on several replicas are all counted
+func (s *databaseStore) Fail(ctx context.Context, identifier string, failedAt time.Time, resetBefore time.Time) (*model.LoginAttempt, error) {
+	// the assignments are applied in key order, failures still sees the previous last_failed_at
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "identifier"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":       gorm.Expr("CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failures + 1 END", resetBefore),
+			"last_failed_at": failedAt,
+		}),
+	}).Create(&model.LoginAttempt{
+		Identifier:   identifier,
+		Failures:     1,
+		LastFailedAt: failedAt,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, identifier)
+}
+
+func (s *databaseStore) Lock(ctx context.Context, identifier string, until time.Time) error {
+	return s.db.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("identifier = ?", identifier).
+		Update("locked_until", until).Error
+}
+
+func (s *databaseStore) Reset(ctx context.Context, identifier string) error {
+	return s.db.WithContext(ctx).Where("identifier = ?", identifier).Delete(&model.LoginAttempt{}).Error
+}
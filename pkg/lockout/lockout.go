@@ -0,0 +1,39 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverMemory   = "memory"
+	DriverDatabase = "database"
+)
+
+// Store keeps the failed login attempts by identifier
+type Store interface {
+	// Get returns the attempts of the identifier, nil when there are none
+	Get(ctx context.Context, identifier string) (*model.LoginAttempt, error)
+	// Fail counts a failure, the count restarts when the previous failure happened before resetBefore
+	Fail(ctx context.Context, identifier string, failedAt time.Time, resetBefore time.Time) (*model.LoginAttempt, error)
+	// Lock refuses the attempts of the identifier until the given time
+	Lock(ctx context.Context, identifier string, until time.Time) error
+	// Reset forgets the attempts of the identifier
+	Reset(ctx context.Context, identifier string) error
+}
+
+// New to instantiate the store of the driver, memory when it is not set.
+// The memory store is local to the process, use database when running several replicas.
+func New(driver string, db *gorm.DB) Store {
+	switch driver {
+	case "", DriverMemory:
+		return NewMemoryStore()
+	case DriverDatabase:
+		return NewDatabaseStore(db)
+	default:
+		panic("error unknown lockout driver " + driver)
+	}
+}
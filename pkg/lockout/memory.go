@@ -0,0 +1,95 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+)
+
+const (
+	sweepInterval = time.Minute
+	// retention keeps idle attempts long enough for any sane window
+	retention = 24 * time.Hour
+)
+
+type memoryStore struct {
+	mu        sync.Mutex
+	attempts  map[string]model.LoginAttempt
+	lastSweep time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		attempts:  map[string]model.LoginAttempt{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryStore) Get(ctx context.Context, identifier string) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[identifier]
+	if !ok {
+		return nil, nil
+	}
+
+	return &attempt, nil
+}
+
+func (s *memoryStore) Fail(ctx context.Context, identifier string, failedAt time.Time, resetBefore time.Time) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[identifier]
+	if !ok || attempt.LastFailedAt.Before(resetBefore) {
+		attempt.Identifier = identifier
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = failedAt
+
+	s.attempts[identifier] = attempt
+	s.sweep()
+
+	return &attempt, nil
+}
+
+func (s *memoryStore) Lock(ctx context.Context, identifier string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := s.attempts[identifier]
+	attempt.Identifier = identifier
+	attempt.LockedUntil = &until
+	s.attempts[identifier] = attempt
+
+	return nil
+}
+
+func (s *memoryStore) Reset(ctx context.Context, identifier string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, identifier)
+
+	return nil
+}
+
+// sweep drops idle attempts, at most once per sweepInterval, the caller must hold the lock
+func (s *memoryStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for identifier, attempt := range s.attempts {
+		locked := attempt.LockedUntil != nil && attempt.LockedUntil.After(now)
+		if !locked && now.Sub(attempt.LastFailedAt) > retention {
+			delete(s.attempts, identifier)
+		}
+	}
+}
@@ -36,6 +36,11 @@ func InitLogger() {
 		Logger()
 }
 
+// Flush to write out buffered log entries before the process exits
+func Flush() {
+	_ = os.Stdout.Sync()
+}
+
 func writeZeroLog(ev *zerolog.Event, tags ...tag.Tag) *zerolog.Event {
 	for _, t := range tags {
 		ev = ev.Str(t.Key, t.Value)
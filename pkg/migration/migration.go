@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"path/filepath"
+
+	"github.com/pressly/goose/v3"
+	"github.com/si-bas/go-rest-boilerplate/database"
+	"gorm.io/gorm"
+)
+
+const (
+	// Dir is the folder of the migration files relative to the project root
+	Dir = "database"
+)
+
+// Run to execute a goose command (up, down, status, redo, ...) with the migrations embedded for the driver
+func Run(command string, db *gorm.DB, driver string, args ...string) error {
+	sqlDb, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if err := goose.SetDialect(driver); err != nil {
+		return err
+	}
+	goose.SetBaseFS(database.Migrations)
+
+	return goose.Run(command, sqlDb, driver, args...)
+}
+
+// Create to write a new blank sql migration for the driver into the source tree
+func Create(driver, name string) error {
+	// create always writes to the os filesystem, the embedded one is read only
+	goose.SetBaseFS(nil)
+
+	return goose.Create(nil, filepath.Join(Dir, driver), name, "sql")
+}
@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+)
+
+const DriverLog = "log"
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to the users, e.g. by email
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// New to instantiate the notifier of the driver, log when it is not set
+func New(driver string) Notifier {
+	switch driver {
+	case "", DriverLog:
+		return NewLogNotifier()
+	default:
+		panic("error unknown notifier driver " + driver)
+	}
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier writes the messages to the log instead of delivering them, only meant for local use
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Send(ctx context.Context, message Message) error {
+	logger.Info(ctx, message.Subject,
+		tag.Tag{Key: "to", Value: message.To},
+		tag.Tag{Key: "body", Value: message.Body},
+	)
+
+	return nil
+}
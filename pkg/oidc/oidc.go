@@ -0,0 +1,423 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/pkg/keyset"
+)
+
+const (
+	// keysRefreshInterval limits how often a token with an unknown kid fetches the keys of the provider again
+	keysRefreshInterval = time.Minute
+
+	// leeway is the clock skew accepted with the provider
+	leeway = time.Minute
+
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrCodeRejected   = errors.New("authorization code is rejected by the provider")
+	ErrInvalidIdToken = errors.New("id token is invalid")
+)
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// Claims of the id token a login relies on
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is the client of an OpenID Connect provider for the authorization code flow with PKCE,
+// the endpoints and the keys are fetched from the issuer on first use
+type Provider struct {
+	config config.OidcProvider
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *discovery
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewProvider(providerConfig config.OidcProvider, client *http.Client) *Provider {
+	return &Provider{
+		config: providerConfig,
+		client: client,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeUrl is where the user logs in, the provider redirects back with the code and the state
+func (p *Provider) AuthCodeUrl(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectUrl)
+	query.Set("scope", strings.Join(p.scopes(), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func (p *Provider) scopes() []string {
+	requested := p.config.Scopes
+	if len(requested) == 0 {
+		requested = []string{"email", "profile"}
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range requested {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// Exchange redeems the code with the verifier of its challenge and returns the id token
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectUrl)
+	form.Set("client_id", p.config.ClientId)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	// client_secret_basic, RFC 6749 section 2.3.1 encodes the credentials before the basic scheme does
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IdToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body)
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+		return "", fmt.Errorf("%w: %s %s", ErrCodeRejected, body.Error, body.ErrorDescription)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("token endpoint responded with status %d", resp.StatusCode)
+	case decodeErr != nil:
+		return "", decodeErr
+	case body.IdToken == "":
+		return "", fmt.Errorf("%w: id token is missing", ErrInvalidIdToken)
+	}
+
+	return body.IdToken, nil
+}
+
+// Verify checks the signature with the keys of the provider, then the issuer, the audience, the expiry and the nonce
+func (p *Provider) Verify(ctx context.Context, idToken string, nonce string, now time.Time) (*Claims, error) {
+	// a failure to fetch the keys is not the fault of the token, it is returned as it is
+	var fetchErr error
+	claims := jwt.MapClaims{}
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		key, err := p.key(ctx, token)
+		if err != nil && !errors.Is(err, ErrInvalidIdToken) {
+			fetchErr = err
+		}
+		return key, err
+	})
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIdToken, err.Error())
+	}
+
+	if iss, _ := claims["iss"].(string); iss != p.config.Issuer {
+		return nil, fmt.Errorf("%w: issuer %s", ErrInvalidIdToken, iss)
+	}
+
+	audience := audienceClaim(claims["aud"])
+	if !contains(audience, p.config.ClientId) {
+		return nil, fmt.Errorf("%w: audience", ErrInvalidIdToken)
+	}
+
+	// a token meant for several clients names the one it was issued to
+	if azp, ok := claims["azp"].(string); (ok || len(audience) > 1) && azp != p.config.ClientId {
+		return nil, fmt.Errorf("%w: authorized party", ErrInvalidIdToken)
+	}
+
+	exp, ok := numericClaim(claims["exp"])
+	if !ok || now.After(time.Unix(exp, 0).Add(leeway)) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidIdToken)
+	}
+
+	if iat, ok := numericClaim(claims["iat"]); ok && time.Unix(iat, 0).After(now.Add(leeway)) {
+		return nil, fmt.Errorf("%w: issued in the future", ErrInvalidIdToken)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce", ErrInvalidIdToken)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: subject is missing", ErrInvalidIdToken)
+	}
+
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+
+	return &Claims{
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: boolClaim(claims["email_verified"]),
+		Name:          name,
+	}, nil
+}
+
+// key to look up the key of the token by its kid, the keys are fetched again when the provider rotated them
+func (p *Provider) key(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.lookupKey(kid)
+	if !ok && time.Since(p.keysFetchedAt) >= keysRefreshInterval {
+		if err := p.fetchKeys(ctx); err != nil {
+			return nil, err
+		}
+		key, ok = p.lookupKey(kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrInvalidIdToken, kid)
+	}
+
+	// the algorithm must fit the key, a symmetric algorithm would let the public key be used as an HMAC secret
+	switch key.(type) {
+	case *rsa.PublicKey:
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			return key, nil
+		}
+	case *ecdsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+			return key, nil
+		}
+	case ed25519.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unexpected signing method %s", ErrInvalidIdToken, token.Method.Alg())
+}
+
+// lookupKey falls back to the only key of the provider when the token has no kid
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) error {
+	d, err := p.discoverLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	var jwks keyset.JWKS
+	if err := p.getJSON(ctx, d.JwksUri, &jwks); err != nil {
+		return err
+	}
+
+	// keys of an unsupported type or meant for encryption are skipped
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	return nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.discoverLocked(ctx)
+}
+
+// discoverLocked reads the configuration of the issuer once, the issuer it declares must be the configured one
+func (p *Provider) discoverLocked(ctx context.Context) (*discovery, error) {
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d discovery
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, err
+	}
+
+	if d.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("provider %s declares issuer %s", p.config.Name, d.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksUri == "" {
+		return nil, fmt.Errorf("provider %s does not declare the endpoints", p.config.Name)
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+// CodeChallenge is the S256 challenge of the code verifier, RFC 7636
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func audienceClaim(aud interface{}) []string {
+	switch aud := aud.(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		audience := []string{}
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+		return audience
+	default:
+		return nil
+	}
+}
+
+func numericClaim(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return int64(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// boolClaim accepts "true" as well, some providers send email_verified as a string
+func boolClaim(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,248 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+const (
+	RulePassword    = "password"
+	RuleUniqueEmail = "unique_email"
+
+	// DefaultLocale is used when none of the locales the client accepts is supported
+	DefaultLocale = "en"
+
+	minPasswordLength = 8
+)
+
+// FieldError is one failed rule of a request field, the field is named as the client sends it
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+var uni *ut.UniversalTranslator
+
+// Init registers the custom rules and the translations of the messages on the validator gin binds with,
+// it is meant to be called once at startup before serving. Gin validates without the request context so the rules
+// needing the database are checked by the services and listed with Violation, e.g. unique_email is the conflict of the insert.
+func Init() error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("binding validator is not go-playground/validator")
+	}
+
+	validate.RegisterTagNameFunc(fieldName)
+
+	if err := validate.RegisterValidation(RulePassword, isStrongPassword); err != nil {
+		return err
+	}
+
+	enLocale := en.New()
+	uni = ut.New(enLocale, enLocale, id.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return err
+	}
+	if err := registerTranslations(validate, enTrans, map[string]string{
+		RulePassword:    "{0} must be at least {1} characters and contain a letter and a digit",
+		RuleUniqueEmail: "{0} is already used",
+	}); err != nil {
+		return err
+	}
+
+	idTrans, _ := uni.GetTranslator("id")
+	if err := idTranslations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		return err
+	}
+	return registerTranslations(validate, idTrans, map[string]string{
+		RulePassword:    "{0} harus berisi minimal {1} karakter dengan huruf dan angka",
+		RuleUniqueEmail: "{0} sudah digunakan",
+	})
+}
+
+// Translate lists the failed rules of err in the first locale of acceptLanguage that is supported,
+// it returns false when err is not a validation error, e.g. a malformed body
+func Translate(err error, acceptLanguage string) ([]FieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+
+	trans := translator(acceptLanguage)
+
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   field(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message,
+		})
+	}
+
+	return fieldErrors, true
+}
+
+// Violation is the failed rule of a field the service found after the request was bound, e.g. unique_email,
+// in the first locale of acceptLanguage that is supported
+func Violation(field, rule, acceptLanguage string) FieldError {
+	message := field + " failed on the " + rule + " rule"
+	if trans := translator(acceptLanguage); trans != nil {
+		if translated, err := trans.T(rule, field); err == nil {
+			message = translated
+		}
+	}
+
+	return FieldError{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+	}
+}
+
+func translator(acceptLanguage string) ut.Translator {
+	if uni == nil {
+		return nil
+	}
+
+	trans, _ := uni.FindTranslator(Locales(acceptLanguage)...)
+	return trans
+}
+
+// Locales parses an Accept-Language header into the locales to try, the most preferred first by their q weight.
+// A region falls back to its language and a locale of q=0 is not acceptable.
+func Locales(acceptLanguage string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	accepted := []weighted{}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(part, ";")
+		locale := strings.TrimSpace(params[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		accepted = append(accepted, weighted{locale: strings.ToLower(strings.ReplaceAll(locale, "-", "_")), q: q})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	locales := make([]string, 0, len(accepted)*2+1)
+	for _, a := range accepted {
+		locales = append(locales, a.locale)
+		if base := strings.SplitN(a.locale, "_", 2)[0]; base != a.locale {
+			locales = append(locales, base)
+		}
+	}
+
+	return append(locales, DefaultLocale)
+}
+
+// field is the path of the field without the name of the request struct, e.g. roles[0]
+func field(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// fieldName names a field by its json, form or uri tag so the errors match what the client sends
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(f.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func isStrongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len([]rune(password)) < minPasswordLength {
+		return false
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	return hasLetter && hasDigit
+}
+
+func registerTranslations(validate *validator.Validate, trans ut.Translator, messages map[string]string) error {
+	for rule, message := range messages {
+		rule, message := rule, message
+		if err := validate.RegisterTranslation(rule, trans, func(t ut.Translator) error {
+			return t.Add(rule, message, true)
+		}, func(t ut.Translator, fe validator.FieldError) string {
+			translated, err := t.T(fe.Tag(), fe.Field(), passwordLength(fe))
+			if err != nil {
+				return fe.Error()
+			}
+			return translated
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// passwordLength fills {1} of the password message, the other messages do not use it
+func passwordLength(fe validator.FieldError) string {
+	if fe.Tag() == RulePassword {
+		return strconv.Itoa(minPasswordLength)
+	}
+	return fe.Param()
+}
@@ -0,0 +1,127 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/validation"
+)
+
+func TestTranslate(t *testing.T) {
+	if err := validation.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name           string
+		payload        model.CreateUserRequest
+		acceptLanguage string
+		want           []validation.FieldError
+	}{
+		{
+			name:    "valid request",
+			payload: model.CreateUserRequest{Name: "user", Email: "user@mail.com", Password: "secret123"},
+		},
+		{
+			name:    "fields are named as in the json",
+			payload: model.CreateUserRequest{Email: "not an email", Password: "secret123"},
+			want: []validation.FieldError{
+				{Field: "name", Rule: "required", Message: "name is a required field"},
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+			},
+		},
+		{
+			name:    "weak password",
+			payload: model.CreateUserRequest{Name: "user", Email: "user@mail.com", Password: "secret"},
+			want: []validation.FieldError{
+				{Field: "password", Rule: validation.RulePassword, Message: "password must be at least 8 characters and contain a letter and a digit"},
+			},
+		},
+		{
+			name:           "translated to the accepted language",
+			payload:        model.CreateUserRequest{Name: "user", Email: "user@mail.com", Password: "secret"},
+			acceptLanguage: "id-ID,id;q=0.9,en;q=0.8",
+			want: []validation.FieldError{
+				{Field: "password", Rule: validation.RulePassword, Message: "password harus berisi minimal 8 karakter dengan huruf dan angka"},
+			},
+		},
+		{
+			name:           "translated to the most preferred language",
+			payload:        model.CreateUserRequest{Name: "user", Email: "user@mail.com", Password: "secret"},
+			acceptLanguage: "en;q=0.5, id;q=0.9",
+			want: []validation.FieldError{
+				{Field: "password", Rule: validation.RulePassword, Message: "password harus berisi minimal 8 karakter dengan huruf dan angka"},
+			},
+		},
+		{
+			name:           "unsupported language falls back to english",
+			payload:        model.CreateUserRequest{Name: "user", Email: "user@mail.com", Password: "secret"},
+			acceptLanguage: "fr-FR, id;q=0",
+			want: []validation.FieldError{
+				{Field: "password", Rule: validation.RulePassword, Message: "password must be at least 8 characters and contain a letter and a digit"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(tc.payload)
+
+			fieldErrors, ok := validation.Translate(err, tc.acceptLanguage)
+			assert.Equal(t, ok, tc.want != nil)
+			assert.Equal(t, fieldErrors, tc.want)
+		})
+	}
+}
+
+func TestViolation(t *testing.T) {
+	if err := validation.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		want           validation.FieldError
+	}{
+		{
+			name: "unique email",
+			want: validation.FieldError{Field: "email", Rule: validation.RuleUniqueEmail, Message: "email is already used"},
+		},
+		{
+			name:           "translated to the accepted language",
+			acceptLanguage: "id-ID,id;q=0.9",
+			want:           validation.FieldError{Field: "email", Rule: validation.RuleUniqueEmail, Message: "email sudah digunakan"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, validation.Violation("email", validation.RuleUniqueEmail, tc.acceptLanguage), tc.want)
+		})
+	}
+}
+
+func TestLocales(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		want           []string
+	}{
+		{name: "no header", acceptLanguage: "", want: []string{"en"}},
+		{name: "region falls back to its language", acceptLanguage: "id-ID,id;q=0.9, *;q=0.1", want: []string{"id_id", "id", "id", "en"}},
+		{name: "sorted by q", acceptLanguage: "en;q=0.3, fr, id;q=0.7", want: []string{"fr", "id", "en", "en"}},
+		{name: "q=0 is not acceptable", acceptLanguage: "id;q=0, fr;q=0.5", want: []string{"fr", "en"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, validation.Locales(tc.acceptLanguage), tc.want)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) CreateApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.CreateApiKeyRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	apiKey, err := h.apiKeyService.Create(ctx, uint32(userId), payload)
+	if err != nil {
+		logger.Warn(ctx, "failed to create api key", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusCreated().StatusCode, result.SetData(apiKey).SetMessage("keep the key safe, it is not shown again"))
+}
+
+func (h *Handler) ListApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	apiKeys, err := h.apiKeyService.List(ctx, uint32(userId))
+	if err != nil {
+		logger.Warn(ctx, "failed to list api keys", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(apiKeys))
+}
+
+func (h *Handler) DetailApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.ApiKeyFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	apiKey, err := h.apiKeyService.Detail(ctx, uint32(userId), payload.ID)
+	if err != nil {
+		logger.Warn(ctx, "failed to get api key detail", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(apiKey))
+}
+
+func (h *Handler) PatchApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.ApiKeyFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	var payload model.PatchApiKeyRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	apiKey, err := h.apiKeyService.Patch(ctx, uint32(userId), uri.ID, payload)
+	if err != nil {
+		logger.Warn(ctx, "failed to update api key", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(apiKey))
+}
+
+func (h *Handler) DeleteApiKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.ApiKeyFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.apiKeyService.Delete(ctx, uint32(userId), payload.ID); err != nil {
+		logger.Warn(ctx, "failed to delete api key", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("api key deleted"))
+}
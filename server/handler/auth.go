@@ -1,15 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
 	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
 	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
 	"github.com/si-bas/go-rest-boilerplate/shared"
 	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
 	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
 )
 
@@ -20,27 +24,91 @@ func (h *Handler) GetToken(c *gin.Context) {
 	var payload model.AuthTokenRequest
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
-		c.JSON(result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.lockoutService.Check(ctx, payload.Email, c.ClientIP()); err != nil {
+		h.lockedOut(c, err)
 		return
 	}
 
 	user, err := h.authService.ValidateUser(ctx, model.ValidateUser(payload))
 	if err != nil {
-		logger.Warn(ctx, fmt.Sprintf("invalid password user: %s", payload.Email), tag.Err(err))
-		c.JSON(result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
+		if err == service.ErrEmailNotVerified {
+			response.Render(c, result.APIStatusForbidden().StatusCode, result.SetError(response.ErrForbiddenResource, err.Error()))
+			return
+		}
+
+		if custErr.Type(err) != service.ErrInvalidCredentials {
+			logger.Warn(ctx, "failed to validate user", tag.Err(err))
+			_ = c.Error(err)
+			return
+		}
+
+		// the reason is only logged, the client cannot tell an unknown email from a wrong password
+		logger.Warn(ctx, fmt.Sprintf("failed login attempt user: %s", payload.Email), tag.Err(err))
+
+		if err := h.lockoutService.Fail(ctx, payload.Email, c.ClientIP()); err != nil {
+			h.lockedOut(c, err)
+			return
+		}
+
+		response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, service.ErrInvalidCredentials.Error()))
+		return
+	}
+
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		logger.Warn(ctx, "failed to check two-factor authentication", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	// the failed attempts are only reset once the second factor is verified, a valid password alone
+	// must not lift the throttling of the codes
+	if twoFactorEnabled {
+		challenge, err := h.authService.GenerateChallengeToken(ctx, user)
+		if err != nil {
+			logger.Warn(ctx, "failed generate challenge token", tag.Err(err))
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(result.APIStatusAccepted().StatusCode, result.SetData(challenge).SetMessage("two-factor code is required"))
 		return
 	}
 
+	if err := h.lockoutService.Succeed(ctx, payload.Email); err != nil {
+		logger.Warn(ctx, "failed to reset login attempts", tag.Err(err))
+	}
+
 	jwtToken, err := h.authService.GenerateToken(ctx, user)
 	if err != nil {
 		logger.Warn(ctx, "failed generate jwt token", tag.Err(err))
-		c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(jwtToken))
 }
 
+// lockedOut responds with the time the client has to wait, any other error of the lockout store is internal
+func (h *Handler) lockedOut(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var lockoutErr *service.LockoutError
+	if !errors.As(err, &lockoutErr) {
+		logger.Warn(ctx, "failed to track login attempts", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Retry-After", strconv.FormatInt(lockoutErr.RetryAfterSeconds(), 10))
+	response.Render(c, result.APIStatusTooManyRequests().StatusCode, result.SetError(response.ErrTooManyRequests, lockoutErr.Error()).SetMeta(lockoutErr.Notice()))
+}
+
 func (h *Handler) RefreshToken(c *gin.Context) {
 	ctx := c.Request.Context()
 	result := response.NewJSONResponse()
@@ -48,39 +116,44 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	var payload model.AuthRefreshTokenRequest
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
-		c.JSON(result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
-	token, err := h.authService.ParseToken(ctx, payload.RefreshToken)
+	jwtToken, err := h.authService.RefreshToken(ctx, payload.RefreshToken)
 	if err != nil {
-		logger.Warn(ctx, err.Error(), tag.Err(err))
-		c.JSON(result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
+		switch err {
+		case service.ErrInvalidRefreshToken, service.ErrInvalidTokenType, service.ErrRefreshTokenRevoked, service.ErrRefreshTokenReused:
+			logger.Warn(ctx, "failed to refresh token", tag.Err(err))
+			response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
+		default:
+			logger.Warn(ctx, "failed generate jwt token", tag.Err(err))
+			_ = c.Error(err)
+		}
 		return
 	}
 
-	claims, err := h.authService.GetClaims(ctx, token)
-	if err != nil {
-		logger.Warn(ctx, err.Error(), tag.Err(err))
-		c.JSON(result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
-		return
-	}
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(jwtToken))
+}
 
-	user, err := h.authService.GetUser(ctx, uint32(claims["sub"].(float64)))
-	if err != nil {
-		logger.Warn(ctx, "failed to get user", tag.Err(err))
-		c.JSON(result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
-		return
-	}
+func (h *Handler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
 
-	jwtToken, err := h.authService.GenerateToken(ctx, user)
-	if err != nil {
-		logger.Warn(ctx, "failed generate jwt token", tag.Err(err))
-		c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
+	if err := h.authService.Logout(ctx,
+		shared.GetContextValueAsString(ctx, constant.SessionID),
+		shared.GetContextValueAsString(ctx, constant.TokenID)); err != nil {
+		if err != service.ErrInvalidTokenType {
+			logger.Warn(ctx, "failed to revoke session", tag.Err(err))
+			_ = c.Error(err)
+			return
+		}
+
+		response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
 		return
 	}
 
-	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(jwtToken))
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("logged out"))
 }
 
 func (h *Handler) GetMe(c *gin.Context) {
@@ -90,16 +163,76 @@ func (h *Handler) GetMe(c *gin.Context) {
 	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
 	if err != nil {
 		logger.Warn(ctx, "failed get user from context", tag.Err(err))
-		c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
+		_ = c.Error(err)
 		return
 	}
 
 	user, err := h.authService.GetUser(ctx, uint32(userId))
 	if err != nil {
 		logger.Warn(ctx, "failed to get user", tag.Err(err))
-		c.JSON(result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
+		if errors.Is(err, custErr.ErrNotFound) {
+			response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, custErr.Message(err)))
+			return
+		}
+
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(user))
 }
+
+func (h *Handler) RevokeUserTokens(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.UserFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.authService.RevokeUserTokens(ctx, payload.ID); err != nil {
+		logger.Warn(ctx, "failed to revoke user tokens", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("tokens revoked"))
+}
+
+// JWKS is not wrapped in the json response, clients expect the key set document as is
+func (h *Handler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.authService.GetJWKS(c.Request.Context()))
+}
+
+func (h *Handler) UnlockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.UserFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	// the body is optional, it only names an ip to unlock as well
+	var request model.UnlockUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+			_ = c.Error(err).SetType(gin.ErrorTypeBind)
+			return
+		}
+	}
+
+	if err := h.lockoutService.Unlock(ctx, payload.ID, request.IP); err != nil {
+		logger.Warn(ctx, "failed to unlock user", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("user unlocked"))
+}
@@ -5,15 +5,39 @@ import (
 )
 
 type Handler struct {
-	userService service.UserService
-	authService service.AuthService
+	userService         service.UserService
+	authService         service.AuthService
+	roleService         service.RoleService
+	lockoutService      service.LockoutService
+	passwordService     service.PasswordService
+	registrationService service.RegistrationService
+	twoFactorService    service.TwoFactorService
+	apiKeyService       service.ApiKeyService
+	oidcService         service.OidcService
+	sessionService      service.SessionService
 }
 
 func New(
 	authService service.AuthService,
-	userService service.UserService) *Handler {
+	userService service.UserService,
+	roleService service.RoleService,
+	lockoutService service.LockoutService,
+	passwordService service.PasswordService,
+	registrationService service.RegistrationService,
+	twoFactorService service.TwoFactorService,
+	apiKeyService service.ApiKeyService,
+	oidcService service.OidcService,
+	sessionService service.SessionService) *Handler {
 	return &Handler{
-		userService: userService,
-		authService: authService,
+		userService:         userService,
+		authService:         authService,
+		roleService:         roleService,
+		lockoutService:      lockoutService,
+		passwordService:     passwordService,
+		registrationService: registrationService,
+		twoFactorService:    twoFactorService,
+		apiKeyService:       apiKeyService,
+		oidcService:         oidcService,
+		sessionService:      sessionService,
 	}
 }
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) ListOidcProvider(c *gin.Context) {
+	result := response.NewJSONResponse()
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(h.oidcService.Providers()))
+}
+
+func (h *Handler) AuthorizeOidc(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.OidcProviderFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	authorization, err := h.oidcService.Authorize(ctx, uri.Provider)
+	if err != nil {
+		if err == service.ErrOidcProviderNotFound {
+			response.Render(c, result.APIStatusNotFound().StatusCode, result.SetError(response.ErrNotFound, err.Error()))
+			return
+		}
+
+		logger.Warn(ctx, "failed to start login at the identity provider", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(authorization))
+}
+
+// OidcCallback issues the tokens for the code the provider returned, a user with two-factor
+// authentication enabled still has to send a code like after a password login
+func (h *Handler) OidcCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.OidcProviderFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	var payload model.OidcCallbackRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	user, err := h.oidcService.Login(ctx, uri.Provider, payload)
+	if err != nil {
+		switch {
+		case err == service.ErrOidcProviderNotFound:
+			response.Render(c, result.APIStatusNotFound().StatusCode, result.SetError(response.ErrNotFound, err.Error()))
+		case err == service.ErrInvalidOidcState:
+			response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, err.Error()))
+		case custErr.Type(err) == service.ErrInvalidOidcLogin:
+			logger.Warn(ctx, "failed login at the identity provider", tag.Err(err))
+			response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, service.ErrInvalidOidcLogin.Error()))
+		case err == service.ErrOidcEmailNotVerified, err == service.ErrEmailNotVerified, err == service.ErrOidcUserNotFound:
+			response.Render(c, result.APIStatusForbidden().StatusCode, result.SetError(response.ErrForbiddenResource, err.Error()))
+		default:
+			logger.Warn(ctx, "failed to login with the identity provider", tag.Err(err))
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		logger.Warn(ctx, "failed to check two-factor authentication", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if twoFactorEnabled {
+		challenge, err := h.authService.GenerateChallengeToken(ctx, user)
+		if err != nil {
+			logger.Warn(ctx, "failed generate challenge token", tag.Err(err))
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(result.APIStatusAccepted().StatusCode, result.SetData(challenge).SetMessage("two-factor code is required"))
+		return
+	}
+
+	jwtToken, err := h.authService.GenerateToken(ctx, user)
+	if err != nil {
+		logger.Warn(ctx, "failed generate jwt token", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(jwtToken))
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.passwordService.ChangePassword(ctx, uint32(userId), payload); err != nil {
+		if err == service.ErrCurrentPasswordInvalid {
+			response.Render(c, result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+			return
+		}
+
+		logger.Warn(ctx, "failed to change password", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("password changed, every session is logged out"))
+}
+
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.passwordService.ForgotPassword(ctx, payload.Email); err != nil {
+		logger.Warn(ctx, "failed to send password reset", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusAccepted().StatusCode, result.SetMessage("a reset link is sent when the email is registered"))
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(ctx, payload); err != nil {
+		if err == service.ErrInvalidResetToken {
+			response.Render(c, result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+			return
+		}
+
+		logger.Warn(ctx, "failed to reset password", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("password reset, every session is logged out"))
+}
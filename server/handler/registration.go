@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) Register(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.RegisterRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	user, err := h.registrationService.Register(ctx, payload)
+	if err != nil {
+		logger.Warn(ctx, "failed to register user", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusCreated().StatusCode, result.SetData(user).SetMessage("a verification link is sent to the email"))
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.registrationService.VerifyEmail(ctx, payload); err != nil {
+		if err == service.ErrInvalidVerificationToken {
+			response.Render(c, result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+			return
+		}
+
+		logger.Warn(ctx, "failed to verify email", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("email verified"))
+}
+
+func (h *Handler) ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.registrationService.ResendVerification(ctx, payload.Email); err != nil {
+		logger.Warn(ctx, "failed to resend email verification", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusAccepted().StatusCode, result.SetMessage("a verification link is sent when the email is registered and not verified"))
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) ListRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	roles, err := h.roleService.List(ctx)
+	if err != nil {
+		logger.Warn(ctx, "failed to get roles", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(roles))
+}
+
+func (h *Handler) ListPermission(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	permissions, err := h.roleService.ListPermissions(ctx)
+	if err != nil {
+		logger.Warn(ctx, "failed to get permissions", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(permissions))
+}
+
+func (h *Handler) GetUserRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.UserFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	roles, err := h.roleService.GetUserRoles(ctx, uri.ID)
+	if err != nil {
+		logger.Warn(ctx, "failed to get user roles", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(roles))
+}
+
+func (h *Handler) SetUserRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.UserFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	var payload model.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	roles, err := h.roleService.SetUserRoles(ctx, uri.ID, payload.Roles)
+	if err != nil {
+		logger.Warn(ctx, "failed to set user roles", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(roles))
+}
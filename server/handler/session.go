@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) ListSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	sessions, err := h.sessionService.List(ctx, uint32(userId), shared.GetContextValueAsString(ctx, constant.SessionID))
+	if err != nil {
+		logger.Warn(ctx, "failed to list sessions", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(sessions))
+}
+
+func (h *Handler) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.SessionFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.sessionService.Revoke(ctx, uint32(userId), payload.ID); err != nil {
+		logger.Warn(ctx, "failed to revoke session", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("session revoked"))
+}
+
+func (h *Handler) RevokeOtherSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.sessionService.RevokeOthers(ctx, uint32(userId), shared.GetContextValueAsString(ctx, constant.SessionID)); err != nil {
+		logger.Warn(ctx, "failed to revoke sessions", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("other sessions revoked"))
+}
+
+func (h *Handler) ListUserSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.UserFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	sessions, err := h.sessionService.List(ctx, payload.ID, "")
+	if err != nil {
+		logger.Warn(ctx, "failed to list sessions", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(sessions))
+}
+
+func (h *Handler) RevokeUserSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.UserSessionFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.sessionService.Revoke(ctx, payload.ID, payload.SessionID); err != nil {
+		logger.Warn(ctx, "failed to revoke session", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("session revoked"))
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func (h *Handler) EnrollTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(ctx, uint32(userId))
+	if err != nil {
+		if err == service.ErrTwoFactorAlreadyEnabled {
+			response.Render(c, result.APIStatusConflict().StatusCode, result.SetError(response.ErrConflict, err.Error()))
+			return
+		}
+
+		logger.Warn(ctx, "failed to enroll two-factor authentication", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(enrollment))
+}
+
+func (h *Handler) ConfirmTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	recoveryCodes, err := h.twoFactorService.Confirm(ctx, uint32(userId), payload.Code)
+	if err != nil {
+		switch err {
+		case service.ErrTwoFactorAlreadyEnabled:
+			response.Render(c, result.APIStatusConflict().StatusCode, result.SetError(response.ErrConflict, err.Error()))
+		case service.ErrTwoFactorNotEnrolled, service.ErrInvalidTwoFactorCode:
+			response.Render(c, result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		default:
+			logger.Warn(ctx, "failed to confirm two-factor authentication", tag.Err(err))
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(recoveryCodes).SetMessage("two-factor authentication enabled, keep the recovery codes safe"))
+}
+
+// DisableTwoFactor is throttled like a login, a stolen access token must not be enough to guess a code
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	userId, err := strconv.ParseUint(shared.GetContextValueAsString(ctx, constant.UserID), 10, 32)
+	if err != nil {
+		logger.Warn(ctx, "failed get user from context", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	user, err := h.authService.GetUser(ctx, uint32(userId))
+	if err != nil {
+		logger.Warn(ctx, "failed to get user", tag.Err(err))
+		if errors.Is(err, custErr.ErrNotFound) {
+			response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, custErr.Message(err)))
+			return
+		}
+
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.lockoutService.Check(ctx, user.Email, c.ClientIP()); err != nil {
+		h.lockedOut(c, err)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(ctx, user.ID, payload.Code); err != nil {
+		switch err {
+		case service.ErrTwoFactorNotEnabled:
+			response.Render(c, result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		case service.ErrInvalidTwoFactorCode:
+			if err := h.lockoutService.Fail(ctx, user.Email, c.ClientIP()); err != nil {
+				h.lockedOut(c, err)
+				return
+			}
+
+			response.Render(c, result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		default:
+			logger.Warn(ctx, "failed to disable two-factor authentication", tag.Err(err))
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("two-factor authentication disabled"))
+}
+
+// VerifyTwoFactor exchanges the challenge token of GetToken and a code for a JwtToken,
+// the failed codes count as failed logins of the email
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	claims, err := h.authService.ValidateChallengeToken(ctx, payload.ChallengeToken)
+	if err != nil {
+		if err != service.ErrInvalidChallenge && err != service.ErrInvalidTokenType {
+			logger.Warn(ctx, "failed to validate challenge token", tag.Err(err))
+			_ = c.Error(err)
+			return
+		}
+
+		response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, service.ErrInvalidChallenge.Error()))
+		return
+	}
+
+	sub, _ := claims["sub"].(float64)
+	user, err := h.authService.GetUser(ctx, uint32(sub))
+	if err != nil {
+		if errors.Is(err, custErr.ErrNotFound) {
+			response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, service.ErrInvalidChallenge.Error()))
+			return
+		}
+
+		logger.Warn(ctx, "failed to get user", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	if err := h.lockoutService.Check(ctx, user.Email, c.ClientIP()); err != nil {
+		h.lockedOut(c, err)
+		return
+	}
+
+	if err := h.twoFactorService.VerifyCode(ctx, user.ID, payload.Code); err != nil {
+		if err != service.ErrInvalidTwoFactorCode && err != service.ErrTwoFactorNotEnabled {
+			logger.Warn(ctx, "failed to verify two-factor code", tag.Err(err))
+			_ = c.Error(err)
+			return
+		}
+
+		logger.Warn(ctx, "failed two-factor attempt", tag.Err(err))
+
+		if err := h.lockoutService.Fail(ctx, user.Email, c.ClientIP()); err != nil {
+			h.lockedOut(c, err)
+			return
+		}
+
+		response.Render(c, result.APIStatusInvalidAuthentication().StatusCode, result.SetError(response.ErrUnauthorized, service.ErrInvalidTwoFactorCode.Error()))
+		return
+	}
+
+	if err := h.lockoutService.Succeed(ctx, user.Email); err != nil {
+		logger.Warn(ctx, "failed to reset login attempts", tag.Err(err))
+	}
+
+	if err := h.authService.RevokeChallengeToken(ctx, claims); err != nil {
+		logger.Warn(ctx, "failed to revoke challenge token", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	jwtToken, err := h.authService.GenerateToken(ctx, user)
+	if err != nil {
+		logger.Warn(ctx, "failed generate jwt token", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(jwtToken))
+}
@@ -7,9 +7,10 @@ import (
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
 	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
 	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
 	"github.com/si-bas/go-rest-boilerplate/shared/helper/pagination"
 	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
-	"gorm.io/gorm"
 )
 
 func (h *Handler) CreateUser(c *gin.Context) {
@@ -19,25 +20,14 @@ func (h *Handler) CreateUser(c *gin.Context) {
 	var payload model.CreateUserRequest
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
-		c.JSON(result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
-		return
-	}
-
-	if emailIsUsed, err := h.userService.EmailIsUsed(ctx, payload.Email); emailIsUsed || err != nil {
-		if err != nil {
-			logger.Error(ctx, "error user email check", err)
-			c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
-			return
-		}
-
-		c.JSON(result.APIStatusConflict().StatusCode, result.SetError(response.ErrConflict, errors.New("email already used").Error()))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	user, err := h.userService.Create(ctx, model.CreateUser(payload))
 	if err != nil {
 		logger.Warn(ctx, "failed to create user", tag.Err(err))
-		c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
+		_ = c.Error(err)
 		return
 	}
 
@@ -51,7 +41,12 @@ func (h *Handler) ListUser(c *gin.Context) {
 	var query model.UserListRequest
 	if err := c.ShouldBindQuery(&query); err != nil {
 		logger.Warn(ctx, "failed to bindQuery", tag.Err(err))
-		c.JSON(result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if query.IncludeDeleted && !shared.HasPermission(ctx, constant.PermissionUserReadDeleted) {
+		response.Render(c, result.APIStatusForbidden().StatusCode, result.SetError(response.ErrForbiddenResource, errors.New("not allowed to list deleted users").Error()))
 		return
 	}
 
@@ -69,6 +64,8 @@ func (h *Handler) ListUser(c *gin.Context) {
 		Keyword: query.Keyword,
 		Name:    query.Name,
 		Email:   query.Email,
+
+		IncludeDeleted: query.IncludeDeleted,
 	}, pagination.Param{
 		Limit: query.Limit,
 		Page:  query.Page,
@@ -76,7 +73,7 @@ func (h *Handler) ListUser(c *gin.Context) {
 	})
 	if err != nil {
 		logger.Warn(ctx, "failed to get users with pagination", tag.Err(err))
-		c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
+		_ = c.Error(err)
 		return
 	}
 
@@ -88,21 +85,120 @@ func (h *Handler) DetailUser(c *gin.Context) {
 	result := response.NewJSONResponse()
 
 	var payload model.UserFind
-	if err := c.BindUri(&payload); err != nil {
+	if err := c.ShouldBindUri(&payload); err != nil {
 		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
-		c.JSON(result.APIStatusBadRequest().StatusCode, result.SetError(response.ErrBadRequest, err.Error()))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
 	user, err := h.userService.Detail(ctx, payload.ID)
 	if err != nil {
-		if err != gorm.ErrRecordNotFound {
-			logger.Warn(ctx, "failed to get user detail", tag.Err(err))
-			c.JSON(result.APIInternalServerError().StatusCode, result.SetError(response.ErrInternalServerError, err.Error()))
-			return
-		}
+		logger.Warn(ctx, "failed to get user detail", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(user))
+}
+
+func (h *Handler) UpdateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.UserFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	var payload model.UpdateUserRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	update := model.UpdateUser{
+		Name:  &payload.Name,
+		Email: &payload.Email,
+	}
+	if payload.Password != "" {
+		update.Password = &payload.Password
+	}
+
+	h.saveUser(c, result, uri.ID, update)
+}
+
+func (h *Handler) PatchUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var uri model.UserFind
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
 
-		c.JSON(result.APIStatusNotFound().StatusCode, result.SetError(response.ErrNotFound, errors.New("user not found").Error()))
+	var payload model.PatchUserRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindJSON", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	h.saveUser(c, result, uri.ID, model.UpdateUser(payload))
+}
+
+func (h *Handler) saveUser(c *gin.Context, result *response.JSONResponse, id uint32, update model.UpdateUser) {
+	ctx := c.Request.Context()
+
+	user, err := h.userService.Update(ctx, id, update)
+	if err != nil {
+		logger.Warn(ctx, "failed to update user", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetData(user))
+}
+
+func (h *Handler) DeleteUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.UserFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	if err := h.userService.Delete(ctx, payload.ID); err != nil {
+		logger.Warn(ctx, "failed to delete user", tag.Err(err))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(result.APIStatusSuccess().StatusCode, result.SetMessage("user deleted"))
+}
+
+func (h *Handler) RestoreUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	result := response.NewJSONResponse()
+
+	var payload model.UserFind
+	if err := c.ShouldBindUri(&payload); err != nil {
+		logger.Warn(ctx, "failed to bindURI", tag.Err(err))
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	user, err := h.userService.Restore(ctx, payload.ID)
+	if err != nil {
+		logger.Warn(ctx, "failed to restore user", tag.Err(err))
+		_ = c.Error(err)
 		return
 	}
 
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+// AuthApiKey authenticates with the X-API-Key header or an Authorization header of the ApiKey scheme,
+// the owner of the key is put in the context as the user and the scopes as its permissions
+func AuthApiKey(apiKeyService service.ApiKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apiKeyFromRequest(c)
+		if key == "" {
+			renderMessage(c, http.StatusUnauthorized, "bad header value given")
+			c.Abort()
+			return
+		}
+
+		principal, err := apiKeyService.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			switch err {
+			case service.ErrInvalidApiKey, service.ErrApiKeyExpired:
+				renderMessage(c, http.StatusUnauthorized, err.Error())
+			default:
+				logger.Warn(c.Request.Context(), "failed to validate api key", tag.Err(err))
+				if response.IsTimeout(c.Request.Context(), err) {
+					renderMessage(c, http.StatusGatewayTimeout, "request timed out")
+				} else {
+					renderMessage(c, http.StatusInternalServerError, "unable to validate api key")
+				}
+			}
+			c.Abort()
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), constant.UserID, strconv.FormatUint(uint64(principal.UserID), 10))
+		ctx = context.WithValue(ctx, constant.ApiKeyID, strconv.FormatUint(uint64(principal.ApiKeyID), 10))
+		ctx = context.WithValue(ctx, constant.Roles, []string{})
+		ctx = context.WithValue(ctx, constant.Permissions, principal.Permissions)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AuthJwtOrApiKey accepts an api key when the request carries one and a jwt otherwise
+func AuthJwtOrApiKey(authService service.AuthService, apiKeyService service.ApiKeyService) gin.HandlerFunc {
+	authJwt := AuthJwt(authService)
+	authApiKey := AuthApiKey(apiKeyService)
+
+	return func(c *gin.Context) {
+		if apiKeyFromRequest(c) != "" {
+			authApiKey(c)
+			return
+		}
+
+		authJwt(c)
+	}
+}
+
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.Request.Header.Get(constant.ApiKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, key, ok := strings.Cut(c.Request.Header.Get(constant.AuthorizationHeader), " ")
+	if ok && strings.EqualFold(scheme, constant.ApiKeyScheme) {
+		return strings.TrimSpace(key)
+	}
+
+	return ""
+}
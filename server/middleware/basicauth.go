@@ -14,13 +14,13 @@ func BasicAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "forbidden"})
+			renderMessage(c, http.StatusUnauthorized, "forbidden")
 			c.Abort()
 			return
 		}
 
 		if username != config.Config.Credential.Username || password != config.Config.Credential.Password {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "forbidden"})
+			renderMessage(c, http.StatusUnauthorized, "forbidden")
 			c.Abort()
 			return
 		}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/pkg/validation"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+// HandleError renders the last error a handler added with c.Error, the kind of a domain error picks the status.
+// A request that failed to bind lists the failed rules of its fields in the language of Accept-Language,
+// so does a used email the service found.
+// Any other error is an internal one, its message is only in the logs of the handler and never sent to the client.
+func HandleError() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		last := c.Errors.Last()
+		err := last.Err
+		result := response.NewJSONResponse()
+
+		switch {
+		case last.IsType(gin.ErrorTypeBind):
+			result.APIStatusBadRequest()
+			if fieldErrors, ok := validation.Translate(err, c.GetHeader("Accept-Language")); ok {
+				result.SetError(response.ErrBadRequest, "request is invalid").SetErrors(fieldErrors)
+			} else {
+				result.SetError(response.ErrBadRequest, err.Error())
+			}
+		case response.IsTimeout(c.Request.Context(), err):
+			result.APIStatusTimeout().SetError(response.ErrTimeoutError)
+		case errors.Is(err, service.ErrEmailAlreadyUsed):
+			result.SetError(err, custErr.Message(err)).SetErrors([]validation.FieldError{
+				validation.Violation("email", validation.RuleUniqueEmail, c.GetHeader("Accept-Language")),
+			})
+			result.SetMessage(constant.StatusText(result.StatusCode))
+		case custErr.Kind(err) != nil:
+			result.SetError(err, custErr.Message(err))
+			result.SetMessage(constant.StatusText(result.StatusCode))
+		default:
+			result.APIInternalServerError().SetError(response.ErrInternalServerError)
+		}
+
+		response.Render(c, result.StatusCode, result)
+	}
+}
+
+// renderMessage writes the message body of the middlewares that guard the routes, as problem details when they are wanted
+func renderMessage(c *gin.Context, code int, message string) {
+	if !response.WantsProblem(c.GetHeader("Accept")) {
+		c.JSON(code, gin.H{"message": message})
+		return
+	}
+
+	var err error
+	switch code {
+	case http.StatusUnauthorized:
+		err = response.ErrUnauthorized
+	case http.StatusForbidden:
+		err = response.ErrForbiddenResource
+	case http.StatusGatewayTimeout:
+		err = response.ErrTimeoutError
+	default:
+		err = response.ErrInternalServerError
+	}
+
+	response.Render(c, code, response.NewJSONResponse().SetError(err, message))
+}
@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/pkg/validation"
+	"github.com/si-bas/go-rest-boilerplate/server/middleware"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func TestHandleErrorUsedEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Config = &config.Cfg{}
+
+	if err := validation.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.Use(middleware.HandleError())
+	router.POST("/user", func(c *gin.Context) {
+		_ = c.Error(service.ErrEmailAlreadyUsed)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/user", nil)
+	request.Header.Set("Accept-Language", "id")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, recorder.Code, http.StatusConflict)
+
+	var result struct {
+		Code   string                  `json:"code"`
+		Errors []validation.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, result.Code, response.StatusCodeConflict)
+	assert.Equal(t, result.Errors, []validation.FieldError{
+		{Field: "email", Rule: validation.RuleUniqueEmail, Message: "email sudah digunakan"},
+	})
+}
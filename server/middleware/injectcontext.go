@@ -18,6 +18,10 @@ func InjectContext() gin.HandlerFunc {
 		c.Request = c.Request.WithContext(logCtx.InjectRequestID(c.Request.Context(), requestID))
 		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), constant.XRequestIDHeader, requestID))
 
+		// the client is recorded with the session a login starts
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), constant.UserAgent, c.Request.UserAgent()))
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), constant.ClientIP, c.ClientIP()))
+
 		c.Next()
 	}
 }
@@ -2,63 +2,80 @@ package middleware
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
-	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/service"
 	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
 )
 
-func AuthJwt() gin.HandlerFunc {
+func AuthJwt(authService service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.Request.Header.Get(constant.AuthorizationHeader)
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "bad header value given"})
+			renderMessage(c, http.StatusUnauthorized, "bad header value given")
 			c.Abort()
 			return
 		}
 
 		splitAuthHeader := strings.Split(authHeader, " ")
 		if len(splitAuthHeader) != 2 {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "incorrectly formatted authorization header"})
+			renderMessage(c, http.StatusUnauthorized, "incorrectly formatted authorization header")
 			c.Abort()
 			return
 		}
 
-		token, err := parseToken(splitAuthHeader[1])
+		claims, err := authService.ValidateAccessToken(c.Request.Context(), splitAuthHeader[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+			switch err {
+			case service.ErrInvalidAccessToken, service.ErrAccessTokenRevoked, service.ErrSessionRevoked, service.ErrTokenExpired,
+				service.ErrTokenNotValidYet, service.ErrInvalidIssuer, service.ErrInvalidAudience:
+				renderMessage(c, http.StatusUnauthorized, err.Error())
+			case service.ErrInvalidTokenType:
+				renderMessage(c, http.StatusUnauthorized, "bad token type")
+			default:
+				logger.Warn(c.Request.Context(), "failed to validate token", tag.Err(err))
+				if response.IsTimeout(c.Request.Context(), err) {
+					renderMessage(c, http.StatusGatewayTimeout, "request timed out")
+				} else {
+					renderMessage(c, http.StatusInternalServerError, "unable to validate token")
+				}
+			}
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"message": "unable to parse claims"})
-			c.Abort()
-		}
-
 		userId := uint32(claims["sub"].(float64))
-		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), constant.UserID, strconv.FormatUint(uint64(userId), 10)))
+		ctx := context.WithValue(c.Request.Context(), constant.UserID, strconv.FormatUint(uint64(userId), 10))
+		ctx = context.WithValue(ctx, constant.SessionID, claimString(claims, "sid"))
+		ctx = context.WithValue(ctx, constant.TokenID, claimString(claims, "jti"))
+		ctx = context.WithValue(ctx, constant.Roles, claimStrings(claims, "roles"))
+		ctx = context.WithValue(ctx, constant.Permissions, claimStrings(claims, "permissions"))
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
 
-func parseToken(jwtToken string) (*jwt.Token, error) {
-	token, err := jwt.Parse(jwtToken, func(token *jwt.Token) (interface{}, error) {
-		if _, OK := token.Method.(*jwt.SigningMethodHMAC); !OK {
-			return nil, errors.New("bad signed method received")
-		}
-		return []byte(config.Config.Jwt.Secret), nil
-	})
+func claimString(claims jwt.MapClaims, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+func claimStrings(claims jwt.MapClaims, key string) []string {
+	values, _ := claims[key].([]interface{})
 
-	if err != nil {
-		return nil, errors.New("bad jwt token")
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			result = append(result, str)
+		}
 	}
 
-	return token, nil
+	return result
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+)
+
+// RequirePermission only lets through users granted every given permission, it must run after AuthJwt
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, permission := range permissions {
+			if !shared.HasPermission(c.Request.Context(), permission) {
+				renderMessage(c, http.StatusForbidden, "missing permission "+permission)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/config"
+)
+
+const defaultRequestTimeout = 30 * time.Second
+
+// Timeout sets the deadline of the request context, the queries bound to it are cancelled once it passes.
+// A route listed in the route timeouts gets its own deadline instead of the default.
+func Timeout() gin.HandlerFunc {
+	timeout := defaultRequestTimeout
+	if config.Config.App.RequestTimeout > 0 {
+		timeout = time.Duration(config.Config.App.RequestTimeout) * time.Second
+	}
+
+	routeTimeouts := map[string]time.Duration{}
+	for _, route := range config.Config.App.RouteTimeouts {
+		if route.Timeout > 0 {
+			routeTimeouts[routeKey(route.Method, route.Path)] = time.Duration(route.Timeout) * time.Second
+		}
+	}
+
+	return func(c *gin.Context) {
+		deadline := timeout
+		if routeTimeout, ok := routeTimeouts[routeKey(c.Request.Method, c.FullPath())]; ok {
+			deadline = routeTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), deadline)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func routeKey(method string, path string) string {
+	return method + " " + path
+}
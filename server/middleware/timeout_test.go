@@ -0,0 +1,121 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	pkgGorm "github.com/si-bas/go-rest-boilerplate/pkg/gorm"
+	"github.com/si-bas/go-rest-boilerplate/pkg/migration"
+	"github.com/si-bas/go-rest-boilerplate/server/middleware"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+func newTimeoutRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	config.Config = &config.Cfg{}
+	config.Config.App.RequestTimeout = 30
+	config.Config.App.RouteTimeouts = []config.RouteTimeout{
+		{Method: http.MethodGet, Path: "/user/:id", Timeout: 1},
+	}
+	config.Config.Db = config.DB{
+		Driver:     pkgGorm.DriverSqlite,
+		Name:       fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name()),
+		Connection: config.DbConnConfig{Open: 1, Idle: 1},
+	}
+
+	db := pkgGorm.ConnectDB()
+	if err := migration.Run("up", db, pkgGorm.DriverSqlite); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pkgGorm.CloseDB(db) })
+
+	userRepo := repository.NewUserRepository(db)
+
+	router := gin.New()
+	router.Use(middleware.Timeout())
+	router.Use(middleware.HandleError())
+
+	deadlineIn := func(c *gin.Context) {
+		deadline, _ := c.Request.Context().Deadline()
+		c.String(http.StatusOK, time.Until(deadline).Round(time.Second).String())
+	}
+	router.GET("/user", deadlineIn)
+	router.POST("/user/:id", deadlineIn)
+
+	// the query is only sent once the deadline of the route has passed
+	router.GET("/user/:id", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		<-ctx.Done()
+
+		if _, err := userRepo.FindById(ctx, 1); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	return router
+}
+
+func TestTimeoutRouteOverride(t *testing.T) {
+	router := newTimeoutRouter(t)
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{name: "default deadline", method: http.MethodGet, path: "/user", want: "30s"},
+		{name: "override is per method", method: http.MethodPost, path: "/user/1", want: "30s"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, recorder.Code, http.StatusOK)
+			assert.Equal(t, recorder.Body.String(), tc.want)
+		})
+	}
+}
+
+func TestTimeoutExpiredQuery(t *testing.T) {
+	router := newTimeoutRouter(t)
+
+	start := time.Now()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user/1", nil))
+
+	// the route deadline of a second applies instead of the default one
+	assert.Equal(t, time.Since(start) < 5*time.Second, true)
+	assert.Equal(t, recorder.Code, http.StatusGatewayTimeout)
+
+	var result response.JSONResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, result.Code, response.StatusCodeTimeoutError)
+}
+
+func TestIsTimeout(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	assert.Equal(t, response.IsTimeout(context.Background(), context.DeadlineExceeded), true)
+	assert.Equal(t, response.IsTimeout(context.Background(), fmt.Errorf("query: %w", context.Canceled)), true)
+	assert.Equal(t, response.IsTimeout(expired, fmt.Errorf("interrupted")), true)
+	assert.Equal(t, response.IsTimeout(context.Background(), fmt.Errorf("syntax error")), false)
+}
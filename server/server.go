@@ -2,20 +2,32 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/si-bas/go-rest-boilerplate/config"
 	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/denylist"
 	"github.com/si-bas/go-rest-boilerplate/pkg/gorm"
+	"github.com/si-bas/go-rest-boilerplate/pkg/keyset"
+	"github.com/si-bas/go-rest-boilerplate/pkg/lockout"
 	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/notifier"
+	"github.com/si-bas/go-rest-boilerplate/pkg/validation"
 	"github.com/si-bas/go-rest-boilerplate/server/handler"
 	"github.com/si-bas/go-rest-boilerplate/server/middleware"
 	"github.com/si-bas/go-rest-boilerplate/service"
 	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	gormDb "gorm.io/gorm"
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 type HTTPServer struct {
 }
 
@@ -25,7 +37,7 @@ func New() *HTTPServer {
 }
 
 func (s *HTTPServer) Start() {
-	h := initHandler()
+	h, authService, apiKeyService, db := initHandler()
 
 	if config.Config.App.Env == constant.EnvProduction {
 		gin.SetMode(gin.ReleaseMode)
@@ -37,26 +49,110 @@ func (s *HTTPServer) Start() {
 	}
 
 	router.Use(middleware.InjectContext())
+	router.Use(middleware.Timeout())
+	router.Use(middleware.HandleError())
 	router.GET("/healthcheck", h.HealthCheck)
+	router.GET("/.well-known/jwks.json", h.JWKS)
 
 	groupV1 := router.Group("/v1")
 	groupV1.POST("/auth/token", h.GetToken)
 	groupV1.POST("/auth/refresh", h.RefreshToken)
+	groupV1.POST("/auth/password/forgot", h.ForgotPassword)
+	groupV1.POST("/auth/password/reset", h.ResetPassword)
+	groupV1.POST("/auth/register", h.Register)
+	groupV1.POST("/auth/verify", h.VerifyEmail)
+	groupV1.POST("/auth/verify/resend", h.ResendVerification)
+	groupV1.POST("/auth/2fa/verify", h.VerifyTwoFactor)
+	groupV1.GET("/auth/oidc", h.ListOidcProvider)
+	groupV1.POST("/auth/oidc/:provider/authorize", h.AuthorizeOidc)
+	groupV1.POST("/auth/oidc/:provider/callback", h.OidcCallback)
+
+	// the account itself is only managed by its user, an api key cannot act on it
+	groupSelf := groupV1.Group("", middleware.AuthJwt(authService))
+	groupSelf.GET("/auth/me", h.GetMe)
+	groupSelf.POST("/auth/logout", h.Logout)
+	groupSelf.POST("/auth/password", h.ChangePassword)
+	groupSelf.POST("/auth/2fa/enroll", h.EnrollTwoFactor)
+	groupSelf.POST("/auth/2fa/confirm", h.ConfirmTwoFactor)
+	groupSelf.POST("/auth/2fa/disable", h.DisableTwoFactor)
+	groupSelf.GET("/auth/sessions", h.ListSession)
+	groupSelf.DELETE("/auth/sessions/:id", h.RevokeSession)
+	groupSelf.POST("/auth/sessions/revoke-others", h.RevokeOtherSessions)
+
+	groupSelf.POST("/api-key", h.CreateApiKey)
+	groupSelf.GET("/api-key", h.ListApiKey)
+	groupSelf.GET("/api-key/:id", h.DetailApiKey)
+	groupSelf.PATCH("/api-key/:id", h.PatchApiKey)
+	groupSelf.DELETE("/api-key/:id", h.DeleteApiKey)
+
+	groupV1.Use(middleware.AuthJwtOrApiKey(authService, apiKeyService))
+
+	groupV1.POST("/user", middleware.RequirePermission(constant.PermissionUserCreate), h.CreateUser)
+	groupV1.GET("/user", middleware.RequirePermission(constant.PermissionUserRead), h.ListUser)
+	groupV1.GET("/user/:id", middleware.RequirePermission(constant.PermissionUserRead), h.DetailUser)
+	groupV1.PUT("/user/:id", middleware.RequirePermission(constant.PermissionUserUpdate), h.UpdateUser)
+	groupV1.PATCH("/user/:id", middleware.RequirePermission(constant.PermissionUserUpdate), h.PatchUser)
+	groupV1.DELETE("/user/:id", middleware.RequirePermission(constant.PermissionUserDelete), h.DeleteUser)
+	groupV1.POST("/user/:id/restore", middleware.RequirePermission(constant.PermissionUserRestore), h.RestoreUser)
+	groupV1.GET("/user/:id/roles", middleware.RequirePermission(constant.PermissionUserRead, constant.PermissionRoleRead), h.GetUserRoles)
+	groupV1.PUT("/user/:id/roles", middleware.RequirePermission(constant.PermissionRoleAssign), h.SetUserRoles)
+	groupV1.POST("/user/:id/revoke-tokens", middleware.RequirePermission(constant.PermissionTokenRevoke), h.RevokeUserTokens)
+	groupV1.POST("/user/:id/unlock", middleware.RequirePermission(constant.PermissionUserUnlock), h.UnlockUser)
+	groupV1.GET("/user/:id/sessions", middleware.RequirePermission(constant.PermissionUserRead), h.ListUserSession)
+	groupV1.DELETE("/user/:id/sessions/:session", middleware.RequirePermission(constant.PermissionTokenRevoke), h.RevokeUserSession)
+
+	groupV1.GET("/role", middleware.RequirePermission(constant.PermissionRoleRead), h.ListRole)
+	groupV1.GET("/permission", middleware.RequirePermission(constant.PermissionRoleRead), h.ListPermission)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", config.Config.App.Port),
+		Handler: router,
+	}
 
-	groupV1.Use(middleware.AuthJwt())
-	groupV1.GET("/auth/me", h.GetMe)
+	go func() {
+		logger.Info(ctx, fmt.Sprintf("listening and serving HTTP on %s", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(ctx, "failed to run router", err)
+			stop()
+		}
+	}()
 
-	groupV1.POST("/user", h.CreateUser)
-	groupV1.GET("/user", h.ListUser)
-	groupV1.GET("/user/:id", h.DetailUser)
+	<-ctx.Done()
+	stop()
 
-	err := router.Run(fmt.Sprintf(":%d", config.Config.App.Port))
-	if err != nil {
-		logger.Error(context.Background(), "failed to run router", err)
+	s.shutdown(srv, db)
+}
+
+// shutdown stops accepting new connections, drains in-flight requests within the grace period,
+// then releases the database pools and flushes the logger
+func (s *HTTPServer) shutdown(srv *http.Server, db *gormDb.DB) {
+	ctx := context.Background()
+	logger.Info(ctx, "shutting down server")
+
+	timeout := defaultShutdownTimeout
+	if config.Config.App.ShutdownTimeout > 0 {
+		timeout = time.Duration(config.Config.App.ShutdownTimeout) * time.Second
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error(ctx, "failed to drain in-flight requests", err)
+	}
+
+	if err := gorm.CloseDB(db); err != nil {
+		logger.Error(ctx, "failed to close database", err)
+	}
+
+	logger.Info(ctx, "server stopped")
+	logger.Flush()
 }
 
-func initHandler() *handler.Handler {
+func initHandler() (*handler.Handler, service.AuthService, service.ApiKeyService, *gormDb.DB) {
 	var err error
 	config.TimeLocation, err = time.LoadLocation(config.Config.App.Timezone)
 	if err != nil {
@@ -70,15 +166,53 @@ func initHandler() *handler.Handler {
 
 	// TODO: init repositories
 	userRepo := repository.NewUserRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	apiKeyRepo := repository.NewApiKeyRepository(db)
+	userIdentityRepo := repository.NewUserIdentityRepository(db)
+	oidcStateRepo := repository.NewOidcStateRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	txManager := repository.NewTxManager(db)
 
 	// TODO: init pkgs
+	tokenDenylist := denylist.New(config.Config.Jwt.Denylist, db)
+	lockoutStore := lockout.New(config.Config.Lockout.Driver, db)
+	notifierClient := notifier.New(config.Config.Notifier.Driver)
+	keySet, err := keyset.New(config.Config.Jwt)
+	if err != nil {
+		panic("error load jwt keys, err=" + err.Error())
+	}
 
 	// TODO: init services
-	authService := service.NewAuthService(userRepo)
-	userService := service.NewUserService(userRepo)
+	authService := service.NewAuthService(userRepo, roleRepo, refreshTokenRepo, sessionRepo, tokenDenylist, keySet)
+	userService := service.NewUserService(userRepo, txManager)
+	roleService := service.NewRoleService(userRepo, roleRepo, permissionRepo)
+	lockoutService := service.NewLockoutService(userRepo, lockoutStore)
+	passwordService := service.NewPasswordService(userRepo, passwordResetRepo, authService, notifierClient, txManager)
+	registrationService := service.NewRegistrationService(userRepo, emailVerificationRepo, notifierClient, txManager)
+	twoFactorService := service.NewTwoFactorService(userRepo, twoFactorRepo, txManager)
+	apiKeyService := service.NewApiKeyService(userRepo, roleRepo, apiKeyRepo)
+	oidcService := service.NewOidcService(userRepo, userIdentityRepo, oidcStateRepo)
+	sessionService := service.NewSessionService(sessionRepo, refreshTokenRepo)
+
+	if err := validation.Init(); err != nil {
+		panic("error init validation, err=" + err.Error())
+	}
 
 	return handler.New(
 		authService,
 		userService,
-	)
+		roleService,
+		lockoutService,
+		passwordService,
+		registrationService,
+		twoFactorService,
+		apiKeyService,
+		oidcService,
+		sessionService,
+	), authService, apiKeyService, db
 }
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"gorm.io/gorm"
+)
+
+type ApiKeyService interface {
+	Create(context.Context, uint32, model.CreateApiKeyRequest) (*model.CreateApiKeyResponse, error)
+	List(context.Context, uint32) ([]model.ApiKey, error)
+	Detail(context.Context, uint32, uint32) (*model.ApiKey, error)
+	Patch(context.Context, uint32, uint32, model.PatchApiKeyRequest) (*model.ApiKey, error)
+	Delete(context.Context, uint32, uint32) error
+	Authenticate(context.Context, string) (*model.ApiKeyPrincipal, error)
+}
+
+const (
+	defaultApiKeyPrefix = "gbk"
+
+	// apiKeyPrefixLength is how many characters of the random part are shown to tell the keys apart
+	apiKeyPrefixLength = 8
+
+	// lastUsedPrecision limits the writes of a busy key to one a minute
+	lastUsedPrecision = time.Minute
+)
+
+var (
+	ErrInvalidApiKey       = custErr.New(custErr.ErrUnauthorized, "api key is invalid")
+	ErrApiKeyExpired       = custErr.New(custErr.ErrUnauthorized, "api key is expired")
+	ErrInvalidApiKeyScopes = custErr.New(custErr.ErrValidation, "scopes must be permissions granted to the owner")
+	ErrInvalidApiKeyExpiry = custErr.New(custErr.ErrValidation, "expiry must be in the future and within the maximum lifetime")
+)
+
+type apiKeyImpl struct {
+	userRepo   repository.UserRepository
+	roleRepo   repository.RoleRepository
+	apiKeyRepo repository.ApiKeyRepository
+	now        func() time.Time
+}
+
+type ApiKeyOption func(*apiKeyImpl)
+
+// WithApiKeyClock to replace the clock the expiry and the last use are computed with
+func WithApiKeyClock(now func() time.Time) ApiKeyOption {
+	return func(s *apiKeyImpl) {
+		s.now = now
+	}
+}
+
+func NewApiKeyService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	apiKeyRepo repository.ApiKeyRepository,
+	opts ...ApiKeyOption) ApiKeyService {
+	s := &apiKeyImpl{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		apiKeyRepo: apiKeyRepo,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Create generates a key for the user, it is only returned here
+func (s *apiKeyImpl) Create(ctx context.Context, userId uint32, payload model.CreateApiKeyRequest) (*model.CreateApiKeyResponse, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	scopes, err := s.validateScopes(ctx, userId, payload.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.validateExpiry(payload.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := config.Config.ApiKey.Prefix
+	if prefix == "" {
+		prefix = defaultApiKeyPrefix
+	}
+	key := prefix + "_" + secret
+
+	apiKey := model.ApiKey{
+		UserID:    userId,
+		Name:      payload.Name,
+		Prefix:    key[:len(prefix)+1+apiKeyPrefixLength],
+		KeyHash:   hashToken(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.Insert(ctx, &apiKey); err != nil {
+		return nil, err
+	}
+
+	return &model.CreateApiKeyResponse{
+		ApiKey: apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (s *apiKeyImpl) List(ctx context.Context, userId uint32) ([]model.ApiKey, error) {
+	return s.apiKeyRepo.GetByUserId(ctx, userId)
+}
+
+func (s *apiKeyImpl) Detail(ctx context.Context, userId uint32, id uint32) (*model.ApiKey, error) {
+	return s.apiKeyRepo.FindByUserIdAndId(ctx, userId, id)
+}
+
+func (s *apiKeyImpl) Patch(ctx context.Context, userId uint32, id uint32, payload model.PatchApiKeyRequest) (*model.ApiKey, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	apiKey, err := s.apiKeyRepo.FindByUserIdAndId(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Name != nil {
+		apiKey.Name = *payload.Name
+	}
+
+	if payload.Scopes != nil {
+		scopes, err := s.validateScopes(ctx, userId, *payload.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.Scopes = scopes
+	}
+
+	if payload.ExpiresAt != nil {
+		expiresAt, err := s.validateExpiry(payload.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.ExpiresAt = expiresAt
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (s *apiKeyImpl) Delete(ctx context.Context, userId uint32, id uint32) error {
+	ctx = repository.WithPrimary(ctx)
+
+	apiKey, err := s.apiKeyRepo.FindByUserIdAndId(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return s.apiKeyRepo.Delete(ctx, apiKey.ID)
+}
+
+// Authenticate resolves the key to its owner, the permissions are the scopes the owner is still granted
+// so removing a role from the owner also takes it from the keys
+func (s *apiKeyImpl) Authenticate(ctx context.Context, key string) (*model.ApiKeyPrincipal, error) {
+	apiKey, err := s.apiKeyRepo.FindByKeyHash(ctx, hashToken(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidApiKey
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, ErrApiKeyExpired
+	}
+
+	if _, err := s.userRepo.FindById(ctx, apiKey.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidApiKey
+		}
+		return nil, err
+	}
+
+	granted, err := s.grantedPermissions(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []string{}
+	for _, scope := range apiKey.Scopes {
+		if granted[scope] {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedPrecision {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+			logger.Warn(ctx, "failed to record api key use", tag.Err(err))
+		}
+	}
+
+	return &model.ApiKeyPrincipal{
+		ApiKeyID:    apiKey.ID,
+		UserID:      apiKey.UserID,
+		Permissions: permissions,
+	}, nil
+}
+
+// validateScopes only allows the permissions the owner is granted, without duplicates
+func (s *apiKeyImpl) validateScopes(ctx context.Context, userId uint32, scopes []string) ([]string, error) {
+	granted, err := s.grantedPermissions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	result := []string{}
+	for _, scope := range scopes {
+		if !granted[scope] {
+			return nil, ErrInvalidApiKeyScopes
+		}
+
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *apiKeyImpl) grantedPermissions(ctx context.Context, userId uint32) (map[string]bool, error) {
+	roles, err := s.roleRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := map[string]bool{}
+	for _, permission := range model.PermissionNames(roles) {
+		granted[permission] = true
+	}
+
+	return granted, nil
+}
+
+// validateExpiry defaults to the maximum lifetime when there is one
+func (s *apiKeyImpl) validateExpiry(expiresAt *time.Time) (*time.Time, error) {
+	now := s.now()
+
+	var limit *time.Time
+	if maxExpiresIn := config.Config.ApiKey.MaxExpiresIn; maxExpiresIn > 0 {
+		maxExpiresAt := now.Add(seconds(maxExpiresIn))
+		limit = &maxExpiresAt
+	}
+
+	if expiresAt == nil {
+		return limit, nil
+	}
+
+	if !expiresAt.After(now) || (limit != nil && expiresAt.After(*limit)) {
+		return nil, ErrInvalidApiKeyExpiry
+	}
+
+	return expiresAt, nil
+}
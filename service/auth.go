@@ -3,14 +3,22 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/si-bas/go-rest-boilerplate/config"
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
 	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/denylist"
+	"github.com/si-bas/go-rest-boilerplate/pkg/keyset"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/shared"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthService interface {
@@ -19,78 +27,600 @@ type AuthService interface {
 	ParseToken(context.Context, string) (*jwt.Token, error)
 	GetClaims(context.Context, *jwt.Token) (jwt.MapClaims, error)
 	GetUser(context.Context, uint32) (*model.User, error)
+	RefreshToken(context.Context, string) (*model.JwtToken, error)
+	Logout(context.Context, string, string) error
+	ValidateAccessToken(context.Context, string) (jwt.MapClaims, error)
+	RevokeUserTokens(context.Context, uint32) error
+	GetJWKS(context.Context) keyset.JWKS
+	GenerateChallengeToken(context.Context, *model.User) (*model.TwoFactorChallenge, error)
+	ValidateChallengeToken(context.Context, string) (jwt.MapClaims, error)
+	RevokeChallengeToken(context.Context, jwt.MapClaims) error
 }
 
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeChallenge = "challenge"
+
+	defaultChallengeExpiresIn = 300
+
+	// maxUserAgentLength is the size of the column the user agent of a session is kept in
+	maxUserAgentLength = 512
+
+	// lastSeenPrecision limits the writes of an active session to one a minute
+	lastSeenPrecision = time.Minute
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("email or password is invalid")
+	ErrInvalidTokenType    = errors.New("token type is invalid")
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid")
+	ErrRefreshTokenRevoked = errors.New("refresh token is revoked")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session is revoked")
+	ErrInvalidAccessToken  = errors.New("bad jwt token")
+	ErrAccessTokenRevoked  = errors.New("token is revoked")
+	ErrTokenExpired        = errors.New("token is expired")
+	ErrTokenNotValidYet    = errors.New("token is not valid yet")
+	ErrInvalidIssuer       = errors.New("token issuer is invalid")
+	ErrInvalidAudience     = errors.New("token audience is invalid")
+	ErrInvalidTokenId      = errors.New("token id is invalid")
+	ErrEmailNotVerified    = errors.New("email is not verified")
+	ErrInvalidChallenge    = errors.New("challenge token is invalid or expired")
+	ErrSessionRevoked      = errors.New("session is revoked")
+)
+
+// dummyPasswordHash is hashed with the cost of the user passwords
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
 type authImpl struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	roleRepo         repository.RoleRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionRepo      repository.SessionRepository
+	tokenDenylist    denylist.TokenDenylist
+	keySet           *keyset.KeySet
+	now              func() time.Time
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return &authImpl{
-		userRepo: userRepo,
+type AuthOption func(*authImpl)
+
+// WithClock to replace the clock the token lifetimes are computed with
+func WithClock(now func() time.Time) AuthOption {
+	return func(s *authImpl) {
+		s.now = now
+	}
+}
+
+func NewAuthService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	tokenDenylist denylist.TokenDenylist,
+	keySet *keyset.KeySet,
+	opts ...AuthOption) AuthService {
+	s := &authImpl{
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		tokenDenylist:    tokenDenylist,
+		keySet:           keySet,
+		now:              time.Now,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
+// ValidateUser returns ErrInvalidCredentials as the type of the error for an unknown email and for a wrong password alike,
+// the reason is only in the message of the chain. An unknown email is checked against a dummy hash so both take as long.
+// ErrEmailNotVerified is only returned once the password matched, unless unverified users are allowed to login.
 func (s *authImpl) ValidateUser(ctx context.Context, prerequisite model.ValidateUser) (*model.User, error) {
 	user, err := s.userRepo.FindByEmail(ctx, prerequisite.Email)
 	if err != nil {
-		return nil, err
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(prerequisite.Password))
+		return nil, custErr.ErrChain{Message: "email is not registered", Cause: err, Type: ErrInvalidCredentials}
 	}
 
-	if user.VerifyPassword(prerequisite.Password) != nil {
-		return nil, errors.New("password is invalid")
+	if err := user.VerifyPassword(prerequisite.Password); err != nil {
+		return nil, custErr.ErrChain{Message: "password is invalid", Cause: err, Type: ErrInvalidCredentials}
+	}
+
+	if user.EmailVerifiedAt == nil && !config.Config.Registration.AllowUnverifiedLogin {
+		return nil, ErrEmailNotVerified
 	}
 	return user, nil
 }
 
+// GenerateToken starts a new session, every refresh token rotated from it shares the same family
 func (s *authImpl) GenerateToken(ctx context.Context, user *model.User) (*model.JwtToken, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+	now := s.now()
+	familyId := uuid.New().String()
+	refreshExpiresAt := s.refreshExpiresAt(now, now, nil)
+
+	jwtToken, err := s.issueToken(ctx, user, familyId, uuid.New().String(), now, refreshExpiresAt)
+	if err != nil {
+		return nil, err
+	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["sub"] = user.ID
+	session := model.Session{
+		ID:         familyId,
+		UserID:     user.ID,
+		LastSeenAt: now,
+		ExpiresAt:  refreshExpiresAt,
+	}
+	setSessionClient(ctx, &session)
+	if err := s.sessionRepo.Insert(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	return jwtToken, nil
+}
+
+func (s *authImpl) issueToken(
+	ctx context.Context,
+	user *model.User,
+	familyId string,
+	refreshJti string,
+	authTime time.Time,
+	refreshExpiresAt time.Time) (*model.JwtToken, error) {
+	roles, err := s.roleRepo.GetByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	// an access token never outlives the session it belongs to
+	expiresAt := now.Add(seconds(config.Config.Jwt.ExpiresIn))
+	if expiresAt.After(refreshExpiresAt) {
+		expiresAt = refreshExpiresAt
+	}
+
+	claims := s.registeredClaims(TokenTypeAccess, user.ID, uuid.New().String(), expiresAt)
 	claims["name"] = user.Name
-	claims["exp"] = time.Now().Add(time.Second * time.Duration(rand.Int31n(config.Config.Jwt.ExpiresIn))).Unix()
+	claims["sid"] = familyId
+	claims["roles"] = model.RoleNames(roles)
+	claims["permissions"] = model.PermissionNames(roles)
 
-	t, err := token.SignedString([]byte(config.Config.Jwt.Secret))
+	t, err := s.keySet.Sign(claims)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
-	rtClaims := refreshToken.Claims.(jwt.MapClaims)
-	rtClaims["sub"] = user.ID
-	rtClaims["exp"] = time.Now().Add(time.Hour * 24).Unix()
-	rtClaims["exp"] = time.Now().Add(time.Second * time.Duration(rand.Int31n(config.Config.Jwt.RefreshExpiresIn))).Unix()
+	rtClaims := s.registeredClaims(TokenTypeRefresh, user.ID, refreshJti, refreshExpiresAt)
+	rtClaims["sid"] = familyId
 
-	rt, err := refreshToken.SignedString([]byte(config.Config.Jwt.Secret))
+	rt, err := s.keySet.Sign(rtClaims)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.refreshTokenRepo.Insert(ctx, &model.RefreshToken{
+		Jti:       refreshJti,
+		FamilyID:  familyId,
+		UserID:    user.ID,
+		AuthTime:  authTime,
+		ExpiresAt: refreshExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	// the claims hold whole seconds, report the lifetime the client will actually observe
+	accessExpiresAt := time.Unix(expiresAt.Unix(), 0)
+	refreshTokenExpiresAt := time.Unix(refreshExpiresAt.Unix(), 0)
+
 	return &model.JwtToken{
 		AccessToken:      t,
-		ExpiresIn:        config.Config.Jwt.ExpiresIn,
+		ExpiresIn:        int32(accessExpiresAt.Unix() - now.Unix()),
+		ExpiresAt:        accessExpiresAt,
 		RefreshToken:     rt,
-		RefreshExpiresIn: config.Config.Jwt.RefreshExpiresIn,
+		RefreshExpiresIn: int32(refreshTokenExpiresAt.Unix() - now.Unix()),
+		RefreshExpiresAt: refreshTokenExpiresAt,
 	}, nil
 }
 
-func (s *authImpl) ParseToken(ctx context.Context, accessToken string) (*jwt.Token, error) {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %s", token.Header["alg"])
+// refreshExpiresAt is when a refresh token issued now expires. Without sliding sessions a rotated
+// token keeps the expiry of the token it replaces, with them every refresh extends it.
+// Either way the session never lasts longer than the maximum session age from the login.
+func (s *authImpl) refreshExpiresAt(now time.Time, authTime time.Time, current *time.Time) time.Time {
+	expiresAt := now.Add(seconds(config.Config.Jwt.RefreshExpiresIn))
+	if current != nil && !config.Config.Jwt.SlidingSession {
+		expiresAt = *current
+	}
+
+	if maxSessionAge := config.Config.Jwt.MaxSessionAge; maxSessionAge > 0 {
+		if limit := authTime.Add(seconds(maxSessionAge)); expiresAt.After(limit) {
+			expiresAt = limit
 		}
+	}
+
+	return expiresAt
+}
+
+func seconds(n int32) time.Duration {
+	return time.Second * time.Duration(n)
+}
+
+// registeredClaims builds the claims every token carries, iss and aud are only set when configured
+func (s *authImpl) registeredClaims(tokenType string, userId uint32, jti string, expiresAt time.Time) jwt.MapClaims {
+	now := s.now().Unix()
 
-		return []byte(config.Config.Jwt.Secret), nil
-	})
+	claims := jwt.MapClaims{
+		"sub": userId,
+		"typ": tokenType,
+		"jti": jti,
+		"iat": now,
+		"nbf": now,
+		"exp": expiresAt.Unix(),
+	}
+
+	if config.Config.Jwt.Issuer != "" {
+		claims["iss"] = config.Config.Jwt.Issuer
+	}
+
+	if config.Config.Jwt.Audience != "" {
+		claims["aud"] = config.Config.Jwt.Audience
+	}
+
+	return claims
+}
 
+// RefreshToken exchanges a refresh token for a new pair, the presented token can only be used once.
+// Presenting an already rotated token again revokes the whole session.
+func (s *authImpl) RefreshToken(ctx context.Context, refreshToken string) (*model.JwtToken, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	token, err := s.ParseToken(ctx, refreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	claims, err := s.GetClaims(ctx, token)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if typ, _ := claims["typ"].(string); typ != TokenTypeRefresh {
+		return nil, ErrInvalidTokenType
+	}
+
+	jti, _ := claims["jti"].(string)
+	stored, err := s.refreshTokenRepo.FindByJti(ctx, jti)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		if stored.ReplacedBy == nil {
+			return nil, ErrRefreshTokenRevoked
+		}
+
+		return nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+	}
+
+	session, err := s.sessionRepo.FindById(ctx, stored.FamilyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefreshTokenRevoked
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil {
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	now := s.now()
+	if now.After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	refreshExpiresAt := s.refreshExpiresAt(now, stored.AuthTime, &stored.ExpiresAt)
+	if !refreshExpiresAt.After(now) {
+		// the maximum session age is reached, the user has to log in again
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindById(ctx, stored.UserID)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	newJti := uuid.New().String()
+	jwtToken, err := s.issueToken(ctx, user, stored.FamilyID, newJti, stored.AuthTime, refreshExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.Rotate(ctx, jti, newJti); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// a concurrent request rotated it first, the token was used twice
+			return nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+		}
+		return nil, err
+	}
+
+	session.LastSeenAt = now
+	session.ExpiresAt = refreshExpiresAt
+	setSessionClient(ctx, session)
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return jwtToken, nil
+}
+
+func (s *authImpl) revokeReusedFamily(ctx context.Context, familyId string) error {
+	if err := s.revokeSession(ctx, familyId); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// revokeSession ends the session and revokes its refresh tokens, the access tokens are rejected with the session
+func (s *authImpl) revokeSession(ctx context.Context, sessionId string) error {
+	if err := s.sessionRepo.Revoke(ctx, sessionId); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(ctx, sessionId)
+}
+
+// setSessionClient records the client of the request, a refresh from a client the context does not know keeps the previous one
+func setSessionClient(ctx context.Context, session *model.Session) {
+	if userAgent := shared.GetContextValueAsString(ctx, constant.UserAgent); userAgent != "" {
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = userAgent[:maxUserAgentLength]
+		}
+		session.UserAgent = userAgent
+	}
+
+	if ip := shared.GetContextValueAsString(ctx, constant.ClientIP); ip != "" {
+		session.IpAddress = ip
+	}
+}
+
+// Logout revokes every refresh token of the session and denies the access token in use
+func (s *authImpl) Logout(ctx context.Context, sessionId string, tokenId string) error {
+	if sessionId == "" || tokenId == "" {
+		return ErrInvalidTokenType
+	}
+
+	if err := s.revokeSession(ctx, sessionId); err != nil {
+		return err
+	}
+
+	return s.tokenDenylist.Revoke(ctx, tokenId, s.accessTokenExpiresAt())
+}
+
+// ValidateAccessToken parses the access token and rejects it when it or its session has been revoked
+func (s *authImpl) ValidateAccessToken(ctx context.Context, accessToken string) (jwt.MapClaims, error) {
+	token, err := s.ParseToken(ctx, accessToken)
+	if err != nil {
+		switch err {
+		case ErrTokenExpired, ErrTokenNotValidYet, ErrInvalidIssuer, ErrInvalidAudience, ErrInvalidTokenType:
+			return nil, err
+		default:
+			return nil, ErrInvalidAccessToken
+		}
+	}
+
+	claims, err := s.GetClaims(ctx, token)
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if typ, _ := claims["typ"].(string); typ != TokenTypeAccess {
+		return nil, ErrInvalidTokenType
+	}
+
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(float64)
+	iat, _ := claims["iat"].(float64)
+	if sub == 0 {
+		return nil, ErrInvalidAccessToken
+	}
+
+	revoked, err := s.tokenDenylist.IsRevoked(ctx, jti, uint32(sub), time.Unix(int64(iat), 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, ErrAccessTokenRevoked
+	}
+
+	// read from the primary, a lagging replica would miss a session just created or let a revoked one through
+	ctx = repository.WithPrimary(ctx)
+
+	sid, _ := claims["sid"].(string)
+	session, err := s.sessionRepo.FindById(ctx, sid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	if now := s.now(); now.Sub(session.LastSeenAt) >= lastSeenPrecision {
+		if err := s.sessionRepo.TouchLastSeen(ctx, session.ID, now); err != nil {
+			logger.Warn(ctx, "failed to record session activity", tag.Err(err))
+		}
+	}
+
+	return claims, nil
+}
+
+// RevokeUserTokens denies every access token issued to the user so far and revokes all of its sessions
+func (s *authImpl) RevokeUserTokens(ctx context.Context, userId uint32) error {
+	ctx = repository.WithPrimary(ctx)
+
+	if _, err := s.userRepo.FindById(ctx, userId); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepo.RevokeByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	return s.tokenDenylist.RevokeUser(ctx, userId, s.now(), s.accessTokenExpiresAt())
+}
+
+// GenerateChallengeToken proves the password was valid, it is exchanged for a JwtToken along with a 2FA code
+func (s *authImpl) GenerateChallengeToken(ctx context.Context, user *model.User) (*model.TwoFactorChallenge, error) {
+	expiresIn := config.Config.TwoFactor.ChallengeExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = defaultChallengeExpiresIn
+	}
+
+	now := s.now()
+	expiresAt := time.Unix(now.Add(seconds(expiresIn)).Unix(), 0)
+
+	t, err := s.keySet.Sign(s.registeredClaims(TokenTypeChallenge, user.ID, uuid.New().String(), expiresAt))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorChallenge{
+		ChallengeToken: t,
+		ExpiresIn:      int32(expiresAt.Unix() - now.Unix()),
+		ExpiresAt:      expiresAt,
+	}, nil
+}
+
+// ValidateChallengeToken parses the challenge token and rejects it when it has been used or the user tokens were revoked
+func (s *authImpl) ValidateChallengeToken(ctx context.Context, challengeToken string) (jwt.MapClaims, error) {
+	token, err := s.ParseToken(ctx, challengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	claims, err := s.GetClaims(ctx, token)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	if typ, _ := claims["typ"].(string); typ != TokenTypeChallenge {
+		return nil, ErrInvalidTokenType
+	}
+
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(float64)
+	iat, _ := claims["iat"].(float64)
+	if sub == 0 {
+		return nil, ErrInvalidChallenge
+	}
+
+	revoked, err := s.tokenDenylist.IsRevoked(ctx, jti, uint32(sub), time.Unix(int64(iat), 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, ErrInvalidChallenge
+	}
+
+	return claims, nil
+}
+
+// RevokeChallengeToken denies the challenge token once it has been exchanged so it is only used once
+func (s *authImpl) RevokeChallengeToken(ctx context.Context, claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+
+	return s.tokenDenylist.Revoke(ctx, jti, time.Unix(int64(exp), 0))
+}
+
+// accessTokenExpiresAt is the latest an access token issued now can expire,
+// denylist entries are useless past it
+func (s *authImpl) accessTokenExpiresAt() time.Time {
+	return s.now().Add(seconds(config.Config.Jwt.ExpiresIn))
+}
+
+func (s *authImpl) ParseToken(ctx context.Context, accessToken string) (*jwt.Token, error) {
+	// the claims are validated below, the parser does not know the leeway nor the expected issuer and audience
+	parser := jwt.Parser{SkipClaimsValidation: true}
+
+	token, err := parser.Parse(accessToken, s.keySet.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.GetClaims(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateClaims(claims); err != nil {
 		return nil, err
 	}
 
 	return token, nil
 }
 
+// validateClaims checks the registered claims, a token must come from this deployment,
+// be meant for it and be within its validity window give or take the configured leeway
+func (s *authImpl) validateClaims(claims jwt.MapClaims) error {
+	now := s.now().Unix()
+	leeway := config.Config.Jwt.Leeway
+
+	if !claims.VerifyExpiresAt(now-leeway, true) {
+		return ErrTokenExpired
+	}
+
+	if !claims.VerifyNotBefore(now+leeway, true) || !claims.VerifyIssuedAt(now+leeway, true) {
+		return ErrTokenNotValidYet
+	}
+
+	if iss, _ := claims["iss"].(string); iss != config.Config.Jwt.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if audience := config.Config.Jwt.Audience; audience == "" {
+		if _, ok := claims["aud"]; ok {
+			return ErrInvalidAudience
+		}
+	} else if !claims.VerifyAudience(audience, true) {
+		return ErrInvalidAudience
+	}
+
+	switch typ, _ := claims["typ"].(string); typ {
+	case TokenTypeAccess, TokenTypeRefresh, TokenTypeChallenge:
+	default:
+		return ErrInvalidTokenType
+	}
+
+	if jti, _ := claims["jti"].(string); jti == "" {
+		return ErrInvalidTokenId
+	}
+
+	return nil
+}
+
 func (s *authImpl) GetClaims(ctx context.Context, token *jwt.Token) (jwt.MapClaims, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
@@ -100,6 +630,11 @@ func (s *authImpl) GetClaims(ctx context.Context, token *jwt.Token) (jwt.MapClai
 	return claims, nil
 }
 
+// GetJWKS to publish the public keys so other services can verify the tokens on their own
+func (s *authImpl) GetJWKS(ctx context.Context) keyset.JWKS {
+	return s.keySet.JWKS()
+}
+
 func (s *authImpl) GetUser(ctx context.Context, id uint32) (*model.User, error) {
 	user, err := s.userRepo.FindById(ctx, id)
 	if err != nil {
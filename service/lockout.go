@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/lockout"
+)
+
+type LockoutService interface {
+	Check(ctx context.Context, email string, ip string) error
+	Fail(ctx context.Context, email string, ip string) error
+	Succeed(ctx context.Context, email string) error
+	Unlock(ctx context.Context, userId uint32, ip string) error
+}
+
+// LockoutError tells the client when it may try to log in again
+type LockoutError struct {
+	LockedUntil time.Time
+	RetryAfter  time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", e.RetryAfterSeconds())
+}
+
+func (e *LockoutError) RetryAfterSeconds() int64 {
+	return int64(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+func (e *LockoutError) Notice() model.LockoutNotice {
+	return model.LockoutNotice{
+		RetryAfter:  e.RetryAfterSeconds(),
+		LockedUntil: e.LockedUntil,
+	}
+}
+
+type lockoutImpl struct {
+	userRepo repository.UserRepository
+	store    lockout.Store
+	now      func() time.Time
+}
+
+type LockoutOption func(*lockoutImpl)
+
+// WithLockoutClock to replace the clock the backoff and lockout are computed with
+func WithLockoutClock(now func() time.Time) LockoutOption {
+	return func(s *lockoutImpl) {
+		s.now = now
+	}
+}
+
+func NewLockoutService(
+	userRepo repository.UserRepository,
+	store lockout.Store,
+	opts ...LockoutOption) LockoutService {
+	s := &lockoutImpl{
+		userRepo: userRepo,
+		store:    store,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Check refuses the attempt while the email or the ip is locked
+func (s *lockoutImpl) Check(ctx context.Context, email string, ip string) error {
+	now := s.now()
+
+	identifiers := []string{emailIdentifier(email)}
+	if ip != "" {
+		identifiers = append(identifiers, ipIdentifier(ip))
+	}
+
+	for _, identifier := range identifiers {
+		attempt, err := s.store.Get(ctx, identifier)
+		if err != nil {
+			return err
+		}
+
+		if attempt != nil && attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			return &LockoutError{
+				LockedUntil: *attempt.LockedUntil,
+				RetryAfter:  attempt.LockedUntil.Sub(now),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Fail counts the failure for the email and the ip. Every failure of an email delays
+// its next attempt exponentially, it is locked out once it reaches the maximum attempts.
+// An ip is only locked out, its threshold is higher as several users may share it.
+// A LockoutError is returned when this failure locked the email or the ip out.
+func (s *lockoutImpl) Fail(ctx context.Context, email string, ip string) error {
+	cfg := config.Config.Lockout
+	now := s.now()
+	resetBefore := now.Add(-seconds(cfg.Window))
+
+	attempt, err := s.store.Fail(ctx, emailIdentifier(email), now, resetBefore)
+	if err != nil {
+		return err
+	}
+
+	var lockoutErr *LockoutError
+
+	switch {
+	case cfg.MaxAttempts > 0 && attempt.Failures >= cfg.MaxAttempts:
+		if lockoutErr, err = s.lock(ctx, attempt.Identifier, now, seconds(cfg.Duration)); err != nil {
+			return err
+		}
+	case cfg.BackoffBase > 0:
+		if _, err = s.lock(ctx, attempt.Identifier, now, backoff(attempt.Failures)); err != nil {
+			return err
+		}
+	}
+
+	if ip == "" || cfg.IPMaxAttempts <= 0 {
+		return errOrNil(lockoutErr)
+	}
+
+	attempt, err = s.store.Fail(ctx, ipIdentifier(ip), now, resetBefore)
+	if err != nil {
+		return err
+	}
+
+	if attempt.Failures >= cfg.IPMaxAttempts {
+		if lockoutErr, err = s.lock(ctx, attempt.Identifier, now, seconds(cfg.Duration)); err != nil {
+			return err
+		}
+	}
+
+	return errOrNil(lockoutErr)
+}
+
+// Succeed forgets the failures of the email, the ones of the ip expire with the window
+// so a valid account cannot be used to clear them
+func (s *lockoutImpl) Succeed(ctx context.Context, email string) error {
+	return s.store.Reset(ctx, emailIdentifier(email))
+}
+
+// Unlock clears the lockout of the user and, when given, of the ip
+func (s *lockoutImpl) Unlock(ctx context.Context, userId uint32, ip string) error {
+	user, err := s.userRepo.FindById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Reset(ctx, emailIdentifier(user.Email)); err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	return s.store.Reset(ctx, ipIdentifier(ip))
+}
+
+func (s *lockoutImpl) lock(ctx context.Context, identifier string, now time.Time, duration time.Duration) (*LockoutError, error) {
+	lockedUntil := now.Add(duration)
+	if err := s.store.Lock(ctx, identifier, lockedUntil); err != nil {
+		return nil, err
+	}
+
+	return &LockoutError{
+		LockedUntil: lockedUntil,
+		RetryAfter:  duration,
+	}, nil
+}
+
+// backoff doubles from the base after every failure, up to the configured maximum
+func backoff(failures int) time.Duration {
+	delay := seconds(config.Config.Lockout.BackoffBase)
+
+	max := seconds(config.Config.Lockout.BackoffMax)
+	if max < delay {
+		max = delay
+	}
+
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
+// errOrNil keeps a nil *LockoutError from becoming a non nil error
+func errOrNil(lockoutErr *LockoutError) error {
+	if lockoutErr == nil {
+		return nil
+	}
+
+	return lockoutErr
+}
+
+func emailIdentifier(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipIdentifier(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/oidc"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"gorm.io/gorm"
+)
+
+type OidcService interface {
+	Providers() []string
+	Authorize(context.Context, string) (*model.OidcAuthorization, error)
+	Login(context.Context, string, model.OidcCallbackRequest) (*model.User, error)
+}
+
+const (
+	defaultOidcStateExpiresIn = 600
+
+	// oidcHttpTimeout bounds every request to a provider
+	oidcHttpTimeout = 10 * time.Second
+)
+
+var (
+	ErrOidcProviderNotFound = errors.New("identity provider is not configured")
+	ErrInvalidOidcState     = errors.New("login state is invalid or expired")
+	ErrInvalidOidcLogin     = errors.New("login at the identity provider is invalid")
+	ErrOidcEmailNotVerified = errors.New("email is not verified by the identity provider")
+	ErrOidcUserNotFound     = errors.New("no user is linked to the identity")
+)
+
+type oidcImpl struct {
+	userRepo         repository.UserRepository
+	userIdentityRepo repository.UserIdentityRepository
+	oidcStateRepo    repository.OidcStateRepository
+	httpClient       *http.Client
+	now              func() time.Time
+	providers        map[string]*oidc.Provider
+	autoRegister     map[string]bool
+}
+
+type OidcOption func(*oidcImpl)
+
+// WithOidcHttpClient to replace the client the providers are called with
+func WithOidcHttpClient(client *http.Client) OidcOption {
+	return func(s *oidcImpl) {
+		s.httpClient = client
+	}
+}
+
+// WithOidcClock to replace the clock the states and the id tokens are checked with
+func WithOidcClock(now func() time.Time) OidcOption {
+	return func(s *oidcImpl) {
+		s.now = now
+	}
+}
+
+func NewOidcService(
+	userRepo repository.UserRepository,
+	userIdentityRepo repository.UserIdentityRepository,
+	oidcStateRepo repository.OidcStateRepository,
+	opts ...OidcOption) OidcService {
+	s := &oidcImpl{
+		userRepo:         userRepo,
+		userIdentityRepo: userIdentityRepo,
+		oidcStateRepo:    oidcStateRepo,
+		httpClient:       &http.Client{Timeout: oidcHttpTimeout},
+		now:              time.Now,
+		providers:        map[string]*oidc.Provider{},
+		autoRegister:     map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, providerConfig := range config.Config.Oidc.Providers {
+		s.providers[providerConfig.Name] = oidc.NewProvider(providerConfig, s.httpClient)
+		s.autoRegister[providerConfig.Name] = providerConfig.AutoRegister
+	}
+
+	return s
+}
+
+// Providers to list the names of the configured providers, e.g. to show the login buttons
+func (s *oidcImpl) Providers() []string {
+	names := []string{}
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Authorize starts a login at the provider, the state is returned to the client and comes back with the code
+func (s *oidcImpl) Authorize(ctx context.Context, providerName string) (*model.OidcAuthorization, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOidcProviderNotFound
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	codeVerifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationUrl, err := provider.AuthCodeUrl(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresIn := config.Config.Oidc.StateExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = defaultOidcStateExpiresIn
+	}
+
+	if err := s.oidcStateRepo.Insert(ctx, &model.OidcState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    s.now().Add(seconds(expiresIn)),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &model.OidcAuthorization{
+		AuthorizationUrl: authorizationUrl,
+		State:            state,
+		ExpiresIn:        int64(expiresIn),
+	}, nil
+}
+
+// Login consumes the state, exchanges the code and returns the user the identity is linked to.
+// An identity seen for the first time is linked to the user of its email when the provider verified it,
+// or to a new user when the provider allows it.
+func (s *oidcImpl) Login(ctx context.Context, providerName string, payload model.OidcCallbackRequest) (*model.User, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOidcProviderNotFound
+	}
+
+	oidcState, err := s.consumeState(ctx, providerName, payload.State)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := provider.Exchange(ctx, payload.Code, oidcState.CodeVerifier)
+	if err != nil {
+		return nil, invalidOidcLogin(err)
+	}
+
+	claims, err := provider.Verify(ctx, idToken, oidcState.Nonce, s.now())
+	if err != nil {
+		return nil, invalidOidcLogin(err)
+	}
+
+	userIdentity, err := s.userIdentityRepo.FindByProviderAndSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		user, err := s.userRepo.FindById(ctx, userIdentity.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOidcUserNotFound
+		}
+		return user, err
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user, err := s.linkedUser(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userIdentityRepo.Insert(ctx, &model.UserIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// consumeState only accepts a state of the provider once and before it expires
+func (s *oidcImpl) consumeState(ctx context.Context, providerName string, state string) (*model.OidcState, error) {
+	oidcState, err := s.oidcStateRepo.FindByStateHash(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOidcState
+		}
+		return nil, err
+	}
+
+	if err := s.oidcStateRepo.Delete(ctx, oidcState.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOidcState
+		}
+		return nil, err
+	}
+
+	if oidcState.Provider != providerName || !s.now().Before(oidcState.ExpiresAt) {
+		return nil, ErrInvalidOidcState
+	}
+
+	return oidcState, nil
+}
+
+// linkedUser finds the user of the email of a new identity. An existing user whose email is not verified
+// is not linked, the account could have been registered by someone else before its owner.
+func (s *oidcImpl) linkedUser(ctx context.Context, provider *oidc.Provider, claims *oidc.Claims) (*model.User, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOidcEmailNotVerified
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	if err == nil {
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrEmailNotVerified
+		}
+		return user, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if !s.autoRegister[provider.Name()] {
+		return nil, ErrOidcUserNotFound
+	}
+
+	// the user logs in at the provider, the password is random so it cannot be used until it is reset
+	password, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.Email
+	}
+
+	verifiedAt := s.now()
+	newUser := model.User{
+		Name:            name,
+		Email:           claims.Email,
+		Password:        password,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if err := s.userRepo.Insert(ctx, &newUser); err != nil {
+		return nil, err
+	}
+
+	return &newUser, nil
+}
+
+// invalidOidcLogin keeps the reason for the log, a provider that cannot be reached stays an internal error
+func invalidOidcLogin(err error) error {
+	if errors.Is(err, oidc.ErrCodeRejected) || errors.Is(err, oidc.ErrInvalidIdToken) {
+		return custErr.ErrChain{Message: "failed to login at the identity provider", Cause: err, Type: ErrInvalidOidcLogin}
+	}
+
+	return err
+}
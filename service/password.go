@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/notifier"
+	"gorm.io/gorm"
+)
+
+type PasswordService interface {
+	ChangePassword(context.Context, uint32, model.ChangePasswordRequest) error
+	ForgotPassword(context.Context, string) error
+	ResetPassword(context.Context, model.ResetPasswordRequest) error
+}
+
+const defaultResetExpiresIn = 3600
+
+var (
+	ErrCurrentPasswordInvalid = errors.New("current password is invalid")
+	ErrInvalidResetToken      = errors.New("reset token is invalid or expired")
+)
+
+type passwordImpl struct {
+	userRepo          repository.UserRepository
+	passwordResetRepo repository.PasswordResetRepository
+	authService       AuthService
+	notifier          notifier.Notifier
+	txManager         repository.TxManager
+}
+
+func NewPasswordService(
+	userRepo repository.UserRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	authService AuthService,
+	notifier notifier.Notifier,
+	txManager repository.TxManager) PasswordService {
+	return &passwordImpl{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		authService:       authService,
+		notifier:          notifier,
+		txManager:         txManager,
+	}
+}
+
+// ChangePassword requires the current password, every session of the user is revoked afterwards
+func (s *passwordImpl) ChangePassword(ctx context.Context, userId uint32, payload model.ChangePasswordRequest) error {
+	ctx = repository.WithPrimary(ctx)
+
+	user, err := s.userRepo.FindById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.VerifyPassword(payload.CurrentPassword) != nil {
+		return ErrCurrentPasswordInvalid
+	}
+
+	return s.setPassword(ctx, user, payload.NewPassword)
+}
+
+// ForgotPassword sends a reset token when the email is registered, it succeeds either way
+// so the response does not tell which emails are registered
+func (s *passwordImpl) ForgotPassword(ctx context.Context, email string) error {
+	ctx = repository.WithPrimary(ctx)
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// only the latest token is valid
+	if err := s.passwordResetRepo.DeleteByUserId(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	expiresIn := config.Config.Password.ResetExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = defaultResetExpiresIn
+	}
+
+	if err := s.passwordResetRepo.Insert(ctx, &model.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(seconds(expiresIn)),
+	}); err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, notifier.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s, use the link below within %d minutes to choose a new password.\n%s",
+			user.Name, expiresIn/60, tokenUrl(config.Config.Password.ResetUrl, token)),
+	})
+}
+
+// ResetPassword consumes the token, every session of the user is revoked afterwards
+func (s *passwordImpl) ResetPassword(ctx context.Context, payload model.ResetPasswordRequest) error {
+	ctx = repository.WithPrimary(ctx)
+
+	passwordReset, err := s.passwordResetRepo.FindByTokenHash(ctx, hashToken(payload.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if passwordReset.UsedAt != nil || time.Now().After(passwordReset.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	// the token is only used up together with the new password, a failed update can be retried with it
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.passwordResetRepo.MarkUsed(ctx, passwordReset.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		user, err := s.userRepo.FindById(ctx, passwordReset.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		return s.setPassword(ctx, user, payload.NewPassword)
+	})
+}
+
+func (s *passwordImpl) setPassword(ctx context.Context, user *model.User, password string) error {
+	user.Password = password
+	if err := user.HashPassword(); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.authService.RevokeUserTokens(ctx, user.ID)
+}
+
+// randomToken returns 32 random bytes, url safe
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is the sha256 of a random token, a slow hash is not needed as the token is not guessable
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenUrl appends the token to the url, the token alone is returned when there is no url
+func tokenUrl(baseUrl string, token string) string {
+	if baseUrl == "" {
+		return token
+	}
+
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return token
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger"
+	"github.com/si-bas/go-rest-boilerplate/pkg/logger/tag"
+	"github.com/si-bas/go-rest-boilerplate/pkg/notifier"
+	"gorm.io/gorm"
+)
+
+type RegistrationService interface {
+	Register(context.Context, model.RegisterRequest) (*model.User, error)
+	VerifyEmail(context.Context, model.VerifyEmailRequest) error
+	ResendVerification(context.Context, string) error
+}
+
+const defaultVerifyExpiresIn = 86400
+
+var (
+	ErrInvalidVerificationToken = errors.New("verification token is invalid or expired")
+)
+
+type registrationImpl struct {
+	userRepo              repository.UserRepository
+	emailVerificationRepo repository.EmailVerificationRepository
+	notifier              notifier.Notifier
+	txManager             repository.TxManager
+}
+
+func NewRegistrationService(
+	userRepo repository.UserRepository,
+	emailVerificationRepo repository.EmailVerificationRepository,
+	notifier notifier.Notifier,
+	txManager repository.TxManager) RegistrationService {
+	return &registrationImpl{
+		userRepo:              userRepo,
+		emailVerificationRepo: emailVerificationRepo,
+		notifier:              notifier,
+		txManager:             txManager,
+	}
+}
+
+// Register creates an unverified user and sends the verification token, a failure to send is only logged
+// as the user can ask for a new token
+func (s *registrationImpl) Register(ctx context.Context, payload model.RegisterRequest) (*model.User, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	newUser := model.User{
+		Name:     payload.Name,
+		Email:    payload.Email,
+		Password: payload.Password,
+	}
+	if err := s.userRepo.Insert(ctx, &newUser); err != nil {
+		return nil, usedEmail(err)
+	}
+
+	if err := s.sendVerification(ctx, &newUser); err != nil {
+		logger.Warn(ctx, "failed to send email verification", tag.Err(err))
+	}
+
+	return &newUser, nil
+}
+
+// VerifyEmail consumes the token and marks the email of its user as verified
+func (s *registrationImpl) VerifyEmail(ctx context.Context, payload model.VerifyEmailRequest) error {
+	ctx = repository.WithPrimary(ctx)
+
+	emailVerification, err := s.emailVerificationRepo.FindByTokenHash(ctx, hashToken(payload.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	if emailVerification.UsedAt != nil || time.Now().After(emailVerification.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+
+	// the token is only used up together with the verification, a failed update can be retried with it
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.emailVerificationRepo.MarkUsed(ctx, emailVerification.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		user, err := s.userRepo.FindById(ctx, emailVerification.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+
+		return s.userRepo.Update(ctx, user)
+	})
+}
+
+// ResendVerification sends a new token when the email is registered and not verified yet, it succeeds either way
+// so the response does not tell which emails are registered
+func (s *registrationImpl) ResendVerification(ctx context.Context, email string) error {
+	ctx = repository.WithPrimary(ctx)
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerification(ctx, user)
+}
+
+func (s *registrationImpl) sendVerification(ctx context.Context, user *model.User) error {
+	// only the latest token is valid
+	if err := s.emailVerificationRepo.DeleteByUserId(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	expiresIn := config.Config.Registration.VerifyExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = defaultVerifyExpiresIn
+	}
+
+	if err := s.emailVerificationRepo.Insert(ctx, &model.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(seconds(expiresIn)),
+	}); err != nil {
+		return err
+	}
+
+	return s.notifier.Send(ctx, notifier.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s, use the link below within %d minutes to verify your email.\n%s",
+			user.Name, expiresIn/60, tokenUrl(config.Config.Registration.VerifyUrl, token)),
+	})
+}
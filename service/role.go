@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+)
+
+type RoleService interface {
+	List(context.Context) ([]model.Role, error)
+	ListPermissions(context.Context) ([]model.Permission, error)
+	GetUserRoles(context.Context, uint32) ([]model.Role, error)
+	SetUserRoles(context.Context, uint32, []string) ([]model.Role, error)
+}
+
+var ErrRoleNotFound = custErr.New(custErr.ErrValidation, "role not found")
+
+type roleImpl struct {
+	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	permissionRepo repository.PermissionRepository
+}
+
+func NewRoleService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository) RoleService {
+	return &roleImpl{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+	}
+}
+
+func (s *roleImpl) List(ctx context.Context) ([]model.Role, error) {
+	return s.roleRepo.GetAll(ctx)
+}
+
+func (s *roleImpl) ListPermissions(ctx context.Context) ([]model.Permission, error) {
+	return s.permissionRepo.GetAll(ctx)
+}
+
+func (s *roleImpl) GetUserRoles(ctx context.Context, userId uint32) ([]model.Role, error) {
+	if _, err := s.userRepo.FindById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.GetByUserId(ctx, userId)
+}
+
+func (s *roleImpl) SetUserRoles(ctx context.Context, userId uint32, names []string) ([]model.Role, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	if _, err := s.userRepo.FindById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	uniqueNames := map[string]bool{}
+	for _, name := range names {
+		uniqueNames[name] = true
+	}
+
+	var roleIds []uint32
+	if len(uniqueNames) > 0 {
+		roles, err := s.roleRepo.GetByNames(ctx, names)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(roles) != len(uniqueNames) {
+			return nil, ErrRoleNotFound
+		}
+
+		for _, role := range roles {
+			roleIds = append(roleIds, role.ID)
+		}
+	}
+
+	if err := s.roleRepo.SetUserRoles(ctx, userId, roleIds); err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.GetByUserId(ctx, userId)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"gorm.io/gorm"
+)
+
+type SessionService interface {
+	List(context.Context, uint32, string) ([]model.Session, error)
+	Revoke(context.Context, uint32, string) error
+	RevokeOthers(context.Context, uint32, string) error
+}
+
+var ErrSessionNotFound = custErr.New(custErr.ErrNotFound, "session not found")
+
+type sessionImpl struct {
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	now              func() time.Time
+}
+
+type SessionOption func(*sessionImpl)
+
+// WithSessionClock to replace the clock the expired sessions are left out with
+func WithSessionClock(now func() time.Time) SessionOption {
+	return func(s *sessionImpl) {
+		s.now = now
+	}
+}
+
+func NewSessionService(
+	sessionRepo repository.SessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	opts ...SessionOption) SessionService {
+	s := &sessionImpl{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		now:              time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// List the active sessions of the user, the one of currentId is flagged so the client can tell it apart
+func (s *sessionImpl) List(ctx context.Context, userId uint32, currentId string) ([]model.Session, error) {
+	sessions, err := s.sessionRepo.GetActiveByUserId(ctx, userId, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = currentId != "" && sessions[i].ID == currentId
+	}
+
+	return sessions, nil
+}
+
+// Revoke logs the user out of the session, it returns ErrSessionNotFound for a session
+// of another user or one that is already revoked
+func (s *sessionImpl) Revoke(ctx context.Context, userId uint32, id string) error {
+	ctx = repository.WithPrimary(ctx)
+
+	session, err := s.sessionRepo.FindByUserIdAndId(ctx, userId, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	if session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(ctx, session.ID)
+}
+
+// RevokeOthers logs the user out of every session but the current one
+func (s *sessionImpl) RevokeOthers(ctx context.Context, userId uint32, currentId string) error {
+	ctx = repository.WithPrimary(ctx)
+
+	sessions, err := s.sessionRepo.GetActiveByUserId(ctx, userId, s.now())
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == currentId {
+			continue
+		}
+
+		// a session revoked concurrently is already logged out
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"gorm.io/gorm"
+)
+
+type apiKeyMock struct {
+	userRepo   repoMocks.UserRepository
+	roleRepo   repoMocks.RoleRepository
+	apiKeyRepo repoMocks.ApiKeyRepository
+}
+
+func apiKeyRoles() []model.Role {
+	return []model.Role{
+		{ID: 1, Name: "reader", Permissions: []model.Permission{{Name: "user:read"}, {Name: "role:read"}}},
+	}
+}
+
+func TestApiKeyCreate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	inDay := now.Add(24 * time.Hour)
+	inYears := now.Add(3 * 365 * 24 * time.Hour)
+	past := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name         string
+		payload      model.CreateApiKeyRequest
+		maxExpiresIn int32
+		scopes       []string
+		expiresAt    *time.Time
+		err          error
+	}{
+		{
+			name:         "scopes are deduplicated and the expiry defaults to the maximum",
+			payload:      model.CreateApiKeyRequest{Name: "ci", Scopes: []string{"user:read", "user:read"}},
+			maxExpiresIn: 3600,
+			scopes:       []string{"user:read"},
+			expiresAt:    func() *time.Time { t := now.Add(time.Hour); return &t }(),
+		},
+		{
+			name:      "never expires without a maximum",
+			payload:   model.CreateApiKeyRequest{Name: "ci", Scopes: []string{"role:read"}},
+			scopes:    []string{"role:read"},
+			expiresAt: nil,
+		},
+		{
+			name:         "expiry within the maximum",
+			payload:      model.CreateApiKeyRequest{Name: "ci", Scopes: []string{"user:read"}, ExpiresAt: &inDay},
+			maxExpiresIn: 31536000,
+			scopes:       []string{"user:read"},
+			expiresAt:    &inDay,
+		},
+		{
+			name:    "scope not granted to the owner",
+			payload: model.CreateApiKeyRequest{Name: "ci", Scopes: []string{"user:delete"}},
+			err:     service.ErrInvalidApiKeyScopes,
+		},
+		{
+			name:         "expiry beyond the maximum",
+			payload:      model.CreateApiKeyRequest{Name: "ci", Scopes: []string{"user:read"}, ExpiresAt: &inYears},
+			maxExpiresIn: 31536000,
+			err:          service.ErrInvalidApiKeyExpiry,
+		},
+		{
+			name:    "expiry in the past",
+			payload: model.CreateApiKeyRequest{Name: "ci", Scopes: []string{"user:read"}, ExpiresAt: &past},
+			err:     service.ErrInvalidApiKeyExpiry,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config.Config = &config.Cfg{}
+			config.Config.ApiKey.Prefix = "test"
+			config.Config.ApiKey.MaxExpiresIn = tc.maxExpiresIn
+
+			listMock := apiKeyMock{}
+			listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return(apiKeyRoles(), nil)
+			listMock.apiKeyRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+			svc := service.NewApiKeyService(&listMock.userRepo, &listMock.roleRepo, &listMock.apiKeyRepo, service.WithApiKeyClock(func() time.Time { return now }))
+			created, err := svc.Create(context.TODO(), 1, tc.payload)
+			assert.Equal(t, tc.err, err)
+			if tc.err != nil {
+				listMock.apiKeyRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+				return
+			}
+
+			// only the hash of the key is stored, the prefix tells the keys apart
+			sum := sha256.Sum256([]byte(created.Key))
+			assert.Equal(t, true, strings.HasPrefix(created.Key, "test_"))
+			assert.Equal(t, hex.EncodeToString(sum[:]), created.KeyHash)
+			assert.Equal(t, created.Key[:len("test_")+8], created.Prefix)
+			assert.Equal(t, uint32(1), created.UserID)
+			assert.Equal(t, tc.scopes, created.Scopes)
+			assert.Equal(t, tc.expiresAt, created.ExpiresAt)
+		})
+	}
+}
+
+func TestApiKeyAuthenticate(t *testing.T) {
+	config.Config = &config.Cfg{}
+
+	now := time.Unix(1700000000, 0)
+	expired := now.Add(-time.Second)
+	justUsed := now.Add(-10 * time.Second)
+	usedLongAgo := now.Add(-time.Hour)
+
+	key := "test_secret"
+	sum := sha256.Sum256([]byte(key))
+	keyHash := hex.EncodeToString(sum[:])
+
+	testCases := []struct {
+		name        string
+		apiKey      *model.ApiKey
+		findErr     error
+		userErr     error
+		permissions []string
+		touched     bool
+		err         error
+	}{
+		{
+			name:        "permissions are the scopes the owner still holds",
+			apiKey:      &model.ApiKey{ID: 5, UserID: 1, Scopes: []string{"user:read", "user:delete"}},
+			permissions: []string{"user:read"},
+			touched:     true,
+		},
+		{
+			name:        "recently used key is not touched again",
+			apiKey:      &model.ApiKey{ID: 5, UserID: 1, Scopes: []string{"role:read"}, LastUsedAt: &justUsed},
+			permissions: []string{"role:read"},
+		},
+		{
+			name:        "key used a while ago is touched",
+			apiKey:      &model.ApiKey{ID: 5, UserID: 1, Scopes: []string{"role:read"}, LastUsedAt: &usedLongAgo},
+			permissions: []string{"role:read"},
+			touched:     true,
+		},
+		{
+			name:    "unknown key",
+			findErr: gorm.ErrRecordNotFound,
+			err:     service.ErrInvalidApiKey,
+		},
+		{
+			name:   "expired key",
+			apiKey: &model.ApiKey{ID: 5, UserID: 1, Scopes: []string{"user:read"}, ExpiresAt: &expired},
+			err:    service.ErrApiKeyExpired,
+		},
+		{
+			name:    "owner is gone",
+			apiKey:  &model.ApiKey{ID: 5, UserID: 1, Scopes: []string{"user:read"}},
+			userErr: gorm.ErrRecordNotFound,
+			err:     service.ErrInvalidApiKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := apiKeyMock{}
+			listMock.apiKeyRepo.On("FindByKeyHash", mock.Anything, keyHash).Return(tc.apiKey, tc.findErr)
+			listMock.apiKeyRepo.On("TouchLastUsed", mock.Anything, uint32(5), now).Return(nil)
+			listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, tc.userErr)
+			listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return(apiKeyRoles(), nil)
+
+			svc := service.NewApiKeyService(&listMock.userRepo, &listMock.roleRepo, &listMock.apiKeyRepo, service.WithApiKeyClock(func() time.Time { return now }))
+			principal, err := svc.Authenticate(context.TODO(), key)
+			assert.Equal(t, tc.err, err)
+			if tc.err != nil {
+				listMock.apiKeyRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.Equal(t, uint32(5), principal.ApiKeyID)
+			assert.Equal(t, uint32(1), principal.UserID)
+			assert.Equal(t, tc.permissions, principal.Permissions)
+
+			if tc.touched {
+				listMock.apiKeyRepo.AssertCalled(t, "TouchLastUsed", mock.Anything, uint32(5), now)
+			} else {
+				listMock.apiKeyRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestApiKeyPatchOtherOwner(t *testing.T) {
+	config.Config = &config.Cfg{}
+
+	listMock := apiKeyMock{}
+	listMock.apiKeyRepo.On("FindByUserIdAndId", mock.Anything, uint32(2), uint32(5)).Return(nil, gorm.ErrRecordNotFound)
+
+	name := "renamed"
+	svc := service.NewApiKeyService(&listMock.userRepo, &listMock.roleRepo, &listMock.apiKeyRepo)
+
+	_, err := svc.Patch(context.TODO(), 2, 5, model.PatchApiKeyRequest{Name: &name})
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	assert.Equal(t, gorm.ErrRecordNotFound, svc.Delete(context.TODO(), 2, 5))
+	listMock.apiKeyRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	listMock.apiKeyRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
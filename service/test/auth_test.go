@@ -2,55 +2,104 @@ package test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-playground/assert/v2"
 	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/mock"
 	"github.com/si-bas/go-rest-boilerplate/config"
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
 	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/pkg/denylist"
+	"github.com/si-bas/go-rest-boilerplate/pkg/keyset"
 	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
 	"gorm.io/gorm"
 )
 
 type authMock struct {
-	userRepo repoMocks.UserRepository
+	userRepo         repoMocks.UserRepository
+	roleRepo         repoMocks.RoleRepository
+	refreshTokenRepo repoMocks.RefreshTokenRepository
+	sessionRepo      repoMocks.SessionRepository
+	tokenDenylist    denylist.TokenDenylist
 }
 
 func TestAuthValidateUser(t *testing.T) {
-	user := model.User{
-		ID:       1,
-		Email:    "newuser@mail.com",
-		Password: "$2a$10$6ItIWM3fUWVmY1GzGU4pzOGUtUVXUbbkHVA1F9fEvlkJchvHU7XF2",
+	config.Config = &config.Cfg{}
+
+	verifiedAt := time.Now()
+	newUser := func() *model.User {
+		return &model.User{
+			ID:              1,
+			Email:           "newuser@mail.com",
+			Password:        "$2a$10$6ItIWM3fUWVmY1GzGU4pzOGUtUVXUbbkHVA1F9fEvlkJchvHU7XF2",
+			EmailVerifiedAt: &verifiedAt,
+		}
 	}
 
 	testCases := []struct {
-		name     string
-		mockFunc func(mock *authMock)
-		wantErr  error
+		name                 string
+		allowUnverifiedLogin bool
+		mockFunc             func(mock *authMock, user *model.User)
+		wantErr              error
 	}{
 		{
 			name: "email and password is valid",
-			mockFunc: func(listMock *authMock) {
-				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(&user, nil)
+			mockFunc: func(listMock *authMock, user *model.User) {
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(user, nil)
+			},
+		},
+		{
+			name: "email is not verified",
+			mockFunc: func(listMock *authMock, user *model.User) {
+				user.EmailVerifiedAt = nil
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(user, nil)
+			},
+			wantErr: service.ErrEmailNotVerified,
+		},
+		{
+			name:                 "email is not verified but unverified login is allowed",
+			allowUnverifiedLogin: true,
+			mockFunc: func(listMock *authMock, user *model.User) {
+				user.EmailVerifiedAt = nil
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(user, nil)
 			},
 		},
 		{
 			name: "email is invalid",
-			mockFunc: func(listMock *authMock) {
+			mockFunc: func(listMock *authMock, user *model.User) {
 				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
 			},
-			wantErr: gorm.ErrRecordNotFound,
+			wantErr: service.ErrInvalidCredentials,
+		},
+		{
+			name: "database error",
+			mockFunc: func(listMock *authMock, user *model.User) {
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, gorm.ErrInvalidDB)
+			},
+			wantErr: gorm.ErrInvalidDB,
 		},
 		{
 			name: "password is invalid",
-			mockFunc: func(listMock *authMock) {
+			mockFunc: func(listMock *authMock, user *model.User) {
 				user.Password = "xxx"
-				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(&user, nil)
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(user, nil)
 			},
-			wantErr: errors.New("password is invalid"),
+			wantErr: service.ErrInvalidCredentials,
 		},
 	}
 
@@ -58,27 +107,53 @@ func TestAuthValidateUser(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			listMock := authMock{
-				userRepo: repoMocks.UserRepository{},
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
 			}
+			// every case gets its own user, a case changing it does not leak into the next one
+			user := newUser()
 			if tc.mockFunc != nil {
-				tc.mockFunc(&listMock)
+				tc.mockFunc(&listMock, user)
 			}
+			config.Config.Registration.AllowUnverifiedLogin = tc.allowUnverifiedLogin
 
-			svc := service.NewAuthService(&listMock.userRepo)
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
 			result, err := svc.ValidateUser(context.TODO(), model.ValidateUser{
 				Email:    user.Email,
 				Password: "admin",
 			})
+			if errType := custErr.Type(err); errType != nil {
+				err = errType
+			}
 			assert.Equal(t, tc.wantErr, err)
 			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
 
 			if err == nil {
-				assert.Equal(t, result, &user)
+				assert.Equal(t, result, user)
 			}
 		})
 	}
 }
 
+func TestAuthValidateUserUnknownEmailHashes(t *testing.T) {
+	listMock := authMock{
+		userRepo: repoMocks.UserRepository{},
+	}
+	listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
+
+	// a bcrypt comparison of the default cost takes tens of milliseconds, skipping it would return at once
+	start := time.Now()
+	_, err := svc.ValidateUser(context.TODO(), model.ValidateUser{Email: "unknown@mail.com", Password: "admin"})
+
+	assert.Equal(t, service.ErrInvalidCredentials, custErr.Type(err))
+	assert.Equal(t, true, time.Since(start) > 10*time.Millisecond)
+}
+
 func TestAuthGenerateToken(t *testing.T) {
 	config.Config = &config.Cfg{}
 	config.Config.Jwt.Secret = "test-secret"
@@ -100,24 +175,52 @@ func TestAuthGenerateToken(t *testing.T) {
 					Name:  "user",
 					Email: "newuser@mail.com",
 				}
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{
+					{Name: "admin", Permissions: []model.Permission{{Name: "user:create"}, {Name: "user:read"}}},
+				}, nil)
+				listMock.refreshTokenRepo.On("Insert", mock.Anything, mock.MatchedBy(func(rt *model.RefreshToken) bool {
+					return rt.UserID == 1 && rt.Jti != "" && rt.FamilyID != ""
+				})).Return(nil)
+				listMock.sessionRepo.On("Insert", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+					return session.UserID == 1 && session.ID != "" && session.UserAgent == "agent" && session.IpAddress == "10.0.0.1"
+				})).Return(nil)
 			},
 		},
+		{
+			name: "failed generate token - error get roles",
+			mockFunc: func(listMock *authMock) {
+				user = model.User{
+					ID:    1,
+					Name:  "user",
+					Email: "newuser@mail.com",
+				}
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return(nil, gorm.ErrInvalidDB)
+			},
+			wantErr: gorm.ErrInvalidDB,
+		},
 	}
 
 	for _, tc := range testCases {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			listMock := authMock{
-				userRepo: repoMocks.UserRepository{},
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
 			}
 			if tc.mockFunc != nil {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewAuthService(&listMock.userRepo)
-			result, err := svc.GenerateToken(context.TODO(), &user)
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
+			ctx := context.WithValue(context.TODO(), constant.UserAgent, "agent")
+			ctx = context.WithValue(ctx, constant.ClientIP, "10.0.0.1")
+			result, err := svc.GenerateToken(ctx, &user)
 			assert.Equal(t, tc.wantErr, err)
 			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
 
 			if err == nil {
 				assert.IsEqual(result, &model.JwtToken{})
@@ -156,17 +259,21 @@ func TestAuthParseToken(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			listMock := authMock{
-				userRepo: repoMocks.UserRepository{},
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
 			}
 			if tc.mockFunc != nil {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewAuthService(&listMock.userRepo)
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
 			result, err := svc.ParseToken(context.TODO(), accessToken)
 
 			assert.IsEqual(tc.wantErr, err)
 			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
 
 			if err == nil {
 				assert.IsEqual(result, &jwt.Token{})
@@ -200,17 +307,21 @@ func TestAuthGetClaims(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			listMock := authMock{
-				userRepo: repoMocks.UserRepository{},
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
 			}
 			if tc.mockFunc != nil {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewAuthService(&listMock.userRepo)
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
 			result, err := svc.GetClaims(context.TODO(), &jwtToken)
 
 			assert.IsEqual(tc.wantErr, err)
 			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
 
 			if err == nil {
 				assert.IsEqual(result, jwt.MapClaims{})
@@ -250,17 +361,21 @@ func TestAuthGetUser(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			listMock := authMock{
-				userRepo: repoMocks.UserRepository{},
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
 			}
 			if tc.mockFunc != nil {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewAuthService(&listMock.userRepo)
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
 			result, err := svc.GetUser(context.TODO(), user.ID)
 
 			assert.Equal(t, tc.wantErr, err)
 			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
 
 			if err == nil {
 				assert.Equal(t, result, &user)
@@ -269,3 +384,848 @@ func TestAuthGetUser(t *testing.T) {
 	}
 
 }
+
+func newKeySet(t *testing.T) *keyset.KeySet {
+	keySet, err := keyset.New(config.Jwt{Secret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keySet
+}
+
+func writePem(t *testing.T, dir string, name string, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// signToken signs the claims, adding the registered claims of a token issued now unless they are given
+func signToken(claims jwt.MapClaims) string {
+	registered := jwt.MapClaims{"iat": time.Now().Unix(), "nbf": time.Now().Unix()}
+	if config.Config.Jwt.Issuer != "" {
+		registered["iss"] = config.Config.Jwt.Issuer
+	}
+	if config.Config.Jwt.Audience != "" {
+		registered["aud"] = config.Config.Jwt.Audience
+	}
+
+	for key, value := range claims {
+		registered[key] = value
+	}
+
+	signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, registered).SignedString([]byte(config.Config.Jwt.Secret))
+	return signed
+}
+
+func TestAuthRefreshToken(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.Secret = "test-secret"
+	config.Config.Jwt.ExpiresIn = 1800
+	config.Config.Jwt.RefreshExpiresIn = 3600
+
+	user := model.User{
+		ID:    1,
+		Name:  "user",
+		Email: "newuser@mail.com",
+	}
+	replacedBy := "next-jti"
+	revokedAt := time.Now()
+	exp := time.Now().Add(time.Hour)
+
+	refreshToken := signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeRefresh, "jti": "jti", "sid": "family", "exp": exp.Unix()})
+
+	testCases := []struct {
+		name     string
+		token    string
+		mockFunc func(mock *authMock)
+		wantErr  error
+	}{
+		{
+			name:  "success rotate refresh token",
+			token: refreshToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(&model.RefreshToken{Jti: "jti", FamilyID: "family", UserID: 1, ExpiresAt: exp}, nil)
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", UserID: 1, UserAgent: "agent", ExpiresAt: exp}, nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{}, nil)
+				listMock.refreshTokenRepo.On("Insert", mock.Anything, mock.MatchedBy(func(rt *model.RefreshToken) bool {
+					return rt.FamilyID == "family" && rt.Jti != "jti"
+				})).Return(nil)
+				listMock.refreshTokenRepo.On("Rotate", mock.Anything, "jti", mock.Anything).Return(nil)
+				listMock.sessionRepo.On("Update", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+					return session.ID == "family" && session.UserAgent == "agent" && !session.LastSeenAt.IsZero()
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "failed refresh token - session revoked",
+			token: refreshToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(&model.RefreshToken{Jti: "jti", FamilyID: "family", UserID: 1, ExpiresAt: exp}, nil)
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", UserID: 1, ExpiresAt: exp, RevokedAt: &revokedAt}, nil)
+			},
+			wantErr: service.ErrRefreshTokenRevoked,
+		},
+		{
+			name:    "failed refresh token - access token given",
+			token:   signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "jti": "access-jti", "sid": "family", "exp": exp.Unix()}),
+			wantErr: service.ErrInvalidTokenType,
+		},
+		{
+			name:    "failed refresh token - bad signature",
+			token:   "xxxx",
+			wantErr: service.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "failed refresh token - unknown token",
+			token: refreshToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "failed refresh token - revoked by logout",
+			token: refreshToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(&model.RefreshToken{Jti: "jti", FamilyID: "family", UserID: 1, ExpiresAt: exp, RevokedAt: &revokedAt}, nil)
+			},
+			wantErr: service.ErrRefreshTokenRevoked,
+		},
+		{
+			name:  "failed refresh token - reused rotated token revokes the family",
+			token: refreshToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(&model.RefreshToken{Jti: "jti", FamilyID: "family", UserID: 1, ExpiresAt: exp, RevokedAt: &revokedAt, ReplacedBy: &replacedBy}, nil)
+				listMock.sessionRepo.On("Revoke", mock.Anything, "family").Return(nil)
+				listMock.refreshTokenRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+			},
+			wantErr: service.ErrRefreshTokenReused,
+		},
+		{
+			name:  "failed refresh token - concurrent rotation revokes the family",
+			token: refreshToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(&model.RefreshToken{Jti: "jti", FamilyID: "family", UserID: 1, ExpiresAt: exp}, nil)
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", UserID: 1, ExpiresAt: exp}, nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{}, nil)
+				listMock.refreshTokenRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+				listMock.refreshTokenRepo.On("Rotate", mock.Anything, "jti", mock.Anything).Return(gorm.ErrRecordNotFound)
+				listMock.sessionRepo.On("Revoke", mock.Anything, "family").Return(nil)
+				listMock.refreshTokenRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+			},
+			wantErr: service.ErrRefreshTokenReused,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := authMock{
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
+			result, err := svc.RefreshToken(context.TODO(), tc.token)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+
+			if err == nil {
+				assert.NotEqual(t, result.RefreshToken, tc.token)
+			}
+		})
+	}
+}
+
+func TestAuthLogout(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.ExpiresIn = 1800
+
+	testCases := []struct {
+		name      string
+		sessionId string
+		tokenId   string
+		mockFunc  func(mock *authMock)
+		wantErr   error
+	}{
+		{
+			name:      "success logout",
+			sessionId: "family",
+			tokenId:   "access-jti",
+			mockFunc: func(listMock *authMock) {
+				listMock.sessionRepo.On("Revoke", mock.Anything, "family").Return(nil)
+				listMock.refreshTokenRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+			},
+		},
+		{
+			name:    "failed logout - token without session",
+			tokenId: "access-jti",
+			wantErr: service.ErrInvalidTokenType,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := authMock{
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
+				tokenDenylist:    denylist.NewMemoryDenylist(),
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
+			err := svc.Logout(context.TODO(), tc.sessionId, tc.tokenId)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+
+			revoked, _ := listMock.tokenDenylist.IsRevoked(context.TODO(), tc.tokenId, 1, time.Now())
+			assert.Equal(t, tc.wantErr == nil, revoked)
+		})
+	}
+}
+
+func TestAuthValidateAccessToken(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.Secret = "test-secret"
+	config.Config.Jwt.ExpiresIn = 1800
+
+	issuedAt := time.Now().Add(-time.Minute)
+	exp := time.Now().Add(time.Hour)
+	accessToken := signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "jti": "jti", "sid": "family", "iat": issuedAt.Unix(), "exp": exp.Unix()})
+
+	testCases := []struct {
+		name        string
+		accessToken string
+		mockFunc    func(mock *authMock)
+		wantErr     error
+	}{
+		{
+			name:        "success validate access token",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", LastSeenAt: time.Now()}, nil)
+			},
+		},
+		{
+			name:        "success validate access token - session read from the primary",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.sessionRepo.On("FindById", mock.MatchedBy(repository.IsPrimary), "family").Return(&model.Session{ID: "family", LastSeenAt: time.Now()}, nil)
+			},
+		},
+		{
+			name:        "success validate access token - user revoked before it was issued",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				_ = listMock.tokenDenylist.RevokeUser(context.TODO(), 1, issuedAt.Add(-time.Minute), exp)
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", LastSeenAt: time.Now()}, nil)
+			},
+		},
+		{
+			name:        "success validate access token - session not seen for a while is touched",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", LastSeenAt: time.Now().Add(-time.Hour)}, nil)
+				listMock.sessionRepo.On("TouchLastSeen", mock.Anything, "family", mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:        "failed validate access token - session revoked",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				revokedAt := time.Now()
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", RevokedAt: &revokedAt}, nil)
+			},
+			wantErr: service.ErrSessionRevoked,
+		},
+		{
+			name:        "failed validate access token - session unknown",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrSessionRevoked,
+		},
+		{
+			name:        "failed validate access token - malformed",
+			accessToken: "xxx",
+			wantErr:     service.ErrInvalidAccessToken,
+		},
+		{
+			name:        "failed validate access token - refresh token given",
+			accessToken: signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeRefresh, "jti": "jti", "exp": exp.Unix()}),
+			wantErr:     service.ErrInvalidTokenType,
+		},
+		{
+			name:        "failed validate access token - without jti",
+			accessToken: signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "exp": exp.Unix()}),
+			wantErr:     service.ErrInvalidAccessToken,
+		},
+		{
+			name:        "failed validate access token - token revoked",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				_ = listMock.tokenDenylist.Revoke(context.TODO(), "jti", exp)
+			},
+			wantErr: service.ErrAccessTokenRevoked,
+		},
+		{
+			name:        "failed validate access token - user tokens revoked",
+			accessToken: accessToken,
+			mockFunc: func(listMock *authMock) {
+				_ = listMock.tokenDenylist.RevokeUser(context.TODO(), 1, time.Now(), exp)
+			},
+			wantErr: service.ErrAccessTokenRevoked,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := authMock{
+				tokenDenylist: denylist.NewMemoryDenylist(),
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
+			claims, err := svc.ValidateAccessToken(context.TODO(), tc.accessToken)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.sessionRepo.AssertExpectations(t)
+			if tc.wantErr == nil {
+				assert.Equal(t, "jti", claims["jti"])
+			}
+		})
+	}
+}
+
+func TestAuthRevokeUserTokens(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.ExpiresIn = 1800
+
+	user := model.User{
+		ID:    1,
+		Name:  "user",
+		Email: "newuser@mail.com",
+	}
+
+	testCases := []struct {
+		name     string
+		mockFunc func(mock *authMock)
+		wantErr  error
+	}{
+		{
+			name: "success revoke user tokens",
+			mockFunc: func(listMock *authMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+				listMock.sessionRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+				listMock.refreshTokenRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+			},
+		},
+		{
+			name: "failed revoke user tokens - user not found",
+			mockFunc: func(listMock *authMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "failed revoke user tokens - revoke sessions error",
+			mockFunc: func(listMock *authMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+				listMock.sessionRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+				listMock.refreshTokenRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(errors.New("unexpected error"))
+			},
+			wantErr: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := authMock{
+				userRepo:         repoMocks.UserRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
+				tokenDenylist:    denylist.NewMemoryDenylist(),
+			}
+			tc.mockFunc(&listMock)
+
+			issuedAt := time.Now().Add(-time.Second)
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t))
+			err := svc.RevokeUserTokens(context.TODO(), user.ID)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+
+			revoked, _ := listMock.tokenDenylist.IsRevoked(context.TODO(), "jti", user.ID, issuedAt)
+			assert.Equal(t, tc.wantErr == nil, revoked)
+		})
+	}
+}
+
+func TestAuthRevokeUserTokensSameSecond(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.Secret = "test-secret"
+	config.Config.Jwt.ExpiresIn = 1800
+
+	// revoked half way through a second, the iat of the tokens only has whole seconds
+	now := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	exp := now.Add(time.Hour)
+
+	listMock := authMock{
+		tokenDenylist: denylist.NewMemoryDenylist(),
+	}
+	listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, nil)
+	listMock.sessionRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+	listMock.refreshTokenRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+	listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{ID: "family", LastSeenAt: now}, nil)
+
+	svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, listMock.tokenDenylist, newKeySet(t),
+		service.WithClock(func() time.Time { return now }))
+	err := svc.RevokeUserTokens(context.TODO(), 1)
+	assert.Equal(t, nil, err)
+
+	// e.g. a login right after the password was changed
+	_, err = svc.ValidateAccessToken(context.TODO(), signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "jti": "new", "sid": "family", "iat": now.Unix(), "exp": exp.Unix()}))
+	assert.Equal(t, nil, err)
+
+	_, err = svc.ValidateAccessToken(context.TODO(), signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "jti": "old", "sid": "family", "iat": now.Unix() - 1, "exp": exp.Unix()}))
+	assert.Equal(t, service.ErrAccessTokenRevoked, err)
+}
+
+func TestAuthKeyRotation(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.ExpiresIn = 1800
+	config.Config.Jwt.RefreshExpiresIn = 3600
+
+	dir := t.TempDir()
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edDer, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDer, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaPublicDer, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+
+	rsaPrivatePath := writePem(t, dir, "previous.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	rsaPublicPath := writePem(t, dir, "previous.pub.pem", "PUBLIC KEY", rsaPublicDer)
+
+	jwtConfig := config.Jwt{
+		SigningKey: "current",
+		Keys: []config.JwtKey{
+			{ID: "current", Algorithm: "EdDSA", PrivateKey: writePem(t, dir, "current.pem", "PRIVATE KEY", edDer)},
+			{ID: "ecdsa", Algorithm: "ES256", PrivateKey: writePem(t, dir, "ecdsa.pem", "PRIVATE KEY", ecDer)},
+			{ID: "previous", Algorithm: "RS256", PublicKey: rsaPublicPath},
+		},
+	}
+	keySet, err := keyset.New(jwtConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previousKeySet, err := keyset.New(config.Jwt{
+		SigningKey: "previous",
+		Keys:       []config.JwtKey{{ID: "previous", Algorithm: "RS256", PrivateKey: rsaPrivatePath}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "jti": "jti", "iat": time.Now().Unix(), "nbf": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix()}
+	previousToken, _ := previousKeySet.Sign(claims)
+
+	unknownToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	unknownToken.Header["kid"] = "unknown"
+	unknownSigned, _ := unknownToken.SignedString([]byte("test-secret"))
+
+	// an HS256 token using the published public key as secret must not validate
+	confusedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	confusedToken.Header["kid"] = "previous"
+	rsaPublicPem, _ := os.ReadFile(rsaPublicPath)
+	confusedSigned, _ := confusedToken.SignedString(rsaPublicPem)
+
+	withoutKid, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+
+	testCases := []struct {
+		name    string
+		token   string
+		wantKid string
+		wantErr bool
+	}{
+		{
+			name:    "success parse token - signed by rotated out key",
+			token:   previousToken,
+			wantKid: "previous",
+		},
+		{
+			name:    "failed parse token - unknown kid",
+			token:   unknownSigned,
+			wantErr: true,
+		},
+		{
+			name:    "failed parse token - algorithm of the key is not respected",
+			token:   confusedSigned,
+			wantErr: true,
+		},
+		{
+			name:    "failed parse token - without kid and secret is not configured",
+			token:   withoutKid,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			svc := service.NewAuthService(&repoMocks.UserRepository{}, &repoMocks.RoleRepository{}, &repoMocks.RefreshTokenRepository{}, &repoMocks.SessionRepository{}, denylist.NewMemoryDenylist(), keySet)
+			token, err := svc.ParseToken(context.TODO(), tc.token)
+
+			assert.Equal(t, tc.wantErr, err != nil)
+			if err == nil {
+				assert.Equal(t, tc.wantKid, token.Header["kid"])
+			}
+		})
+	}
+
+	t.Run("success generate token - signed by the signing key", func(t *testing.T) {
+		listMock := authMock{
+			roleRepo:         repoMocks.RoleRepository{},
+			refreshTokenRepo: repoMocks.RefreshTokenRepository{},
+		}
+		listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{}, nil)
+		listMock.refreshTokenRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+		listMock.sessionRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+		svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, denylist.NewMemoryDenylist(), keySet)
+		result, err := svc.GenerateToken(context.TODO(), &model.User{ID: 1, Name: "user"})
+		assert.Equal(t, nil, err)
+
+		token, err := svc.ParseToken(context.TODO(), result.AccessToken)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "current", token.Header["kid"])
+		assert.Equal(t, "EdDSA", token.Method.Alg())
+	})
+
+	t.Run("success get jwks - every public key without the secret", func(t *testing.T) {
+		svc := service.NewAuthService(&repoMocks.UserRepository{}, &repoMocks.RoleRepository{}, &repoMocks.RefreshTokenRepository{}, &repoMocks.SessionRepository{}, denylist.NewMemoryDenylist(), keySet)
+		jwks := svc.GetJWKS(context.TODO())
+
+		assert.Equal(t, 3, len(jwks.Keys))
+		assert.Equal(t, "current", jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "ecdsa", jwks.Keys[1].Kid)
+		assert.Equal(t, "P-256", jwks.Keys[1].Crv)
+		assert.Equal(t, "previous", jwks.Keys[2].Kid)
+		assert.Equal(t, "AQAB", jwks.Keys[2].E)
+	})
+}
+
+func TestAuthParseTokenClaims(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.Secret = "test-secret"
+	config.Config.Jwt.Issuer = "https://api.example.com"
+	config.Config.Jwt.Audience = "production"
+	config.Config.Jwt.Leeway = 30
+
+	now := time.Now()
+	exp := now.Add(time.Hour).Unix()
+
+	testCases := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr error
+	}{
+		{
+			name:   "success parse token",
+			claims: jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "exp": exp},
+		},
+		{
+			name:   "success parse token - expired within leeway",
+			claims: jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "exp": now.Add(-10 * time.Second).Unix()},
+		},
+		{
+			name:   "success parse token - audience list",
+			claims: jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "aud": []string{"other", "production"}, "exp": exp},
+		},
+		{
+			name:    "failed parse token - expired",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "exp": now.Add(-time.Minute).Unix()},
+			wantErr: service.ErrTokenExpired,
+		},
+		{
+			name:    "failed parse token - without exp",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti"},
+			wantErr: service.ErrTokenExpired,
+		},
+		{
+			name:    "failed parse token - not valid yet",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "nbf": now.Add(time.Minute).Unix(), "exp": exp},
+			wantErr: service.ErrTokenNotValidYet,
+		},
+		{
+			name:    "failed parse token - issued in the future",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "iat": now.Add(time.Minute).Unix(), "exp": exp},
+			wantErr: service.ErrTokenNotValidYet,
+		},
+		{
+			name:    "failed parse token - issued by another deployment",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "iss": "https://staging.example.com", "exp": exp},
+			wantErr: service.ErrInvalidIssuer,
+		},
+		{
+			name:    "failed parse token - meant for another audience",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "jti": "jti", "aud": "staging", "exp": exp},
+			wantErr: service.ErrInvalidAudience,
+		},
+		{
+			name:    "failed parse token - unknown type",
+			claims:  jwt.MapClaims{"typ": "other", "jti": "jti", "exp": exp},
+			wantErr: service.ErrInvalidTokenType,
+		},
+		{
+			name:    "failed parse token - without jti",
+			claims:  jwt.MapClaims{"typ": service.TokenTypeAccess, "exp": exp},
+			wantErr: service.ErrInvalidTokenId,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			svc := service.NewAuthService(&repoMocks.UserRepository{}, &repoMocks.RoleRepository{}, &repoMocks.RefreshTokenRepository{}, &repoMocks.SessionRepository{}, denylist.NewMemoryDenylist(), newKeySet(t))
+			_, err := svc.ParseToken(context.TODO(), signToken(tc.claims))
+
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestAuthTokenLifetime(t *testing.T) {
+	loginAt := time.Unix(time.Now().Unix(), 0)
+	user := model.User{ID: 1, Name: "user"}
+
+	testCases := []struct {
+		name                 string
+		maxSessionAge        int32
+		wantExpiresIn        int32
+		wantRefreshExpiresIn int32
+	}{
+		{
+			name:                 "success generate token - exact lifetimes",
+			wantExpiresIn:        900,
+			wantRefreshExpiresIn: 3600,
+		},
+		{
+			name:                 "success generate token - capped by the maximum session age",
+			maxSessionAge:        600,
+			wantExpiresIn:        600,
+			wantRefreshExpiresIn: 600,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config.Config = &config.Cfg{}
+			config.Config.Jwt.ExpiresIn = 900
+			config.Config.Jwt.RefreshExpiresIn = 3600
+			config.Config.Jwt.MaxSessionAge = tc.maxSessionAge
+
+			listMock := authMock{
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
+			}
+			listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{}, nil)
+			listMock.refreshTokenRepo.On("Insert", mock.Anything, mock.MatchedBy(func(refreshToken *model.RefreshToken) bool {
+				return refreshToken.AuthTime.Equal(loginAt) &&
+					refreshToken.ExpiresAt.Equal(loginAt.Add(time.Duration(tc.wantRefreshExpiresIn)*time.Second))
+			})).Return(nil)
+			listMock.sessionRepo.On("Insert", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+				return session.LastSeenAt.Equal(loginAt) &&
+					session.ExpiresAt.Equal(loginAt.Add(time.Duration(tc.wantRefreshExpiresIn)*time.Second))
+			})).Return(nil)
+
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, denylist.NewMemoryDenylist(), newKeySet(t),
+				service.WithClock(func() time.Time { return loginAt }))
+			result, err := svc.GenerateToken(context.TODO(), &user)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.wantExpiresIn, result.ExpiresIn)
+			assert.Equal(t, loginAt.Add(time.Duration(tc.wantExpiresIn)*time.Second), result.ExpiresAt)
+			assert.Equal(t, tc.wantRefreshExpiresIn, result.RefreshExpiresIn)
+			assert.Equal(t, loginAt.Add(time.Duration(tc.wantRefreshExpiresIn)*time.Second), result.RefreshExpiresAt)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+
+			token, err := svc.ParseToken(context.TODO(), result.AccessToken)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, float64(result.ExpiresAt.Unix()), token.Claims.(jwt.MapClaims)["exp"])
+		})
+	}
+}
+
+func TestAuthRefreshTokenLifetime(t *testing.T) {
+	loginAt := time.Unix(time.Now().Unix(), 0)
+	user := model.User{ID: 1, Name: "user"}
+
+	testCases := []struct {
+		name                 string
+		slidingSession       bool
+		maxSessionAge        int32
+		elapsed              time.Duration
+		storedExpiresAt      time.Time
+		wantRefreshExpiresAt time.Time
+		wantExpiresIn        int32
+		wantErr              error
+	}{
+		{
+			name:                 "success refresh token - keeps the expiry of the session",
+			elapsed:              1000 * time.Second,
+			storedExpiresAt:      loginAt.Add(3600 * time.Second),
+			wantRefreshExpiresAt: loginAt.Add(3600 * time.Second),
+			wantExpiresIn:        900,
+		},
+		{
+			name:                 "success refresh token - access token capped by the session expiry",
+			elapsed:              3000 * time.Second,
+			storedExpiresAt:      loginAt.Add(3600 * time.Second),
+			wantRefreshExpiresAt: loginAt.Add(3600 * time.Second),
+			wantExpiresIn:        600,
+		},
+		{
+			name:                 "success refresh token - sliding session is extended",
+			slidingSession:       true,
+			elapsed:              1000 * time.Second,
+			storedExpiresAt:      loginAt.Add(3600 * time.Second),
+			wantRefreshExpiresAt: loginAt.Add(4600 * time.Second),
+			wantExpiresIn:        900,
+		},
+		{
+			name:                 "success refresh token - sliding session capped by the maximum session age",
+			slidingSession:       true,
+			maxSessionAge:        4000,
+			elapsed:              1000 * time.Second,
+			storedExpiresAt:      loginAt.Add(3600 * time.Second),
+			wantRefreshExpiresAt: loginAt.Add(4000 * time.Second),
+			wantExpiresIn:        900,
+		},
+		{
+			name:            "failed refresh token - maximum session age reached",
+			slidingSession:  true,
+			maxSessionAge:   4000,
+			elapsed:         4000 * time.Second,
+			storedExpiresAt: loginAt.Add(5000 * time.Second),
+			wantErr:         service.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config.Config = &config.Cfg{}
+			config.Config.Jwt.Secret = "test-secret"
+			config.Config.Jwt.ExpiresIn = 900
+			config.Config.Jwt.RefreshExpiresIn = 3600
+			config.Config.Jwt.SlidingSession = tc.slidingSession
+			config.Config.Jwt.MaxSessionAge = tc.maxSessionAge
+
+			now := loginAt.Add(tc.elapsed)
+			refreshToken := signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeRefresh, "jti": "jti", "sid": "family",
+				"iat": loginAt.Unix(), "nbf": loginAt.Unix(), "exp": tc.storedExpiresAt.Unix()})
+
+			listMock := authMock{
+				userRepo:         repoMocks.UserRepository{},
+				roleRepo:         repoMocks.RoleRepository{},
+				refreshTokenRepo: repoMocks.RefreshTokenRepository{},
+			}
+			listMock.refreshTokenRepo.On("FindByJti", mock.Anything, "jti").Return(&model.RefreshToken{
+				Jti: "jti", FamilyID: "family", UserID: 1, AuthTime: loginAt, ExpiresAt: tc.storedExpiresAt,
+			}, nil)
+			listMock.sessionRepo.On("FindById", mock.Anything, "family").Return(&model.Session{
+				ID: "family", UserID: 1, LastSeenAt: loginAt, ExpiresAt: tc.storedExpiresAt,
+			}, nil)
+			if tc.wantErr == nil {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{}, nil)
+				listMock.refreshTokenRepo.On("Insert", mock.Anything, mock.MatchedBy(func(refreshToken *model.RefreshToken) bool {
+					return refreshToken.AuthTime.Equal(loginAt) && refreshToken.ExpiresAt.Equal(tc.wantRefreshExpiresAt)
+				})).Return(nil)
+				listMock.refreshTokenRepo.On("Rotate", mock.Anything, "jti", mock.Anything).Return(nil)
+				listMock.sessionRepo.On("Update", mock.Anything, mock.MatchedBy(func(session *model.Session) bool {
+					return session.LastSeenAt.Equal(now) && session.ExpiresAt.Equal(tc.wantRefreshExpiresAt)
+				})).Return(nil)
+			}
+
+			svc := service.NewAuthService(&listMock.userRepo, &listMock.roleRepo, &listMock.refreshTokenRepo, &listMock.sessionRepo, denylist.NewMemoryDenylist(), newKeySet(t),
+				service.WithClock(func() time.Time { return now }))
+			result, err := svc.RefreshToken(context.TODO(), refreshToken)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+
+			if err == nil {
+				assert.Equal(t, tc.wantExpiresIn, result.ExpiresIn)
+				assert.Equal(t, tc.wantRefreshExpiresAt, result.RefreshExpiresAt)
+				assert.Equal(t, int32(tc.wantRefreshExpiresAt.Sub(now).Seconds()), result.RefreshExpiresIn)
+			}
+		})
+	}
+}
+
+func TestAuthChallengeToken(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.Secret = "test-secret"
+	config.Config.Jwt.ExpiresIn = 900
+	config.Config.TwoFactor.ChallengeExpiresIn = 120
+
+	user := model.User{ID: 1, Email: "user@mail.com"}
+	svc := service.NewAuthService(&repoMocks.UserRepository{}, &repoMocks.RoleRepository{}, &repoMocks.RefreshTokenRepository{}, &repoMocks.SessionRepository{}, denylist.NewMemoryDenylist(), newKeySet(t))
+
+	challenge, err := svc.GenerateChallengeToken(context.TODO(), &user)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, int32(120), challenge.ExpiresIn)
+
+	claims, err := svc.ValidateChallengeToken(context.TODO(), challenge.ChallengeToken)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, float64(1), claims["sub"])
+
+	// a challenge token cannot be used in place of the tokens it is exchanged for
+	_, err = svc.ValidateAccessToken(context.TODO(), challenge.ChallengeToken)
+	assert.Equal(t, service.ErrInvalidTokenType, err)
+
+	_, err = svc.RefreshToken(context.TODO(), challenge.ChallengeToken)
+	assert.Equal(t, service.ErrInvalidTokenType, err)
+
+	accessToken := signToken(jwt.MapClaims{"sub": 1, "typ": service.TokenTypeAccess, "jti": "jti", "exp": time.Now().Add(time.Minute).Unix()})
+	_, err = svc.ValidateChallengeToken(context.TODO(), accessToken)
+	assert.Equal(t, service.ErrInvalidTokenType, err)
+
+	// it is only exchanged once
+	assert.Equal(t, svc.RevokeChallengeToken(context.TODO(), claims), nil)
+	_, err = svc.ValidateChallengeToken(context.TODO(), challenge.ChallengeToken)
+	assert.Equal(t, service.ErrInvalidChallenge, err)
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/pkg/lockout"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"gorm.io/gorm"
+)
+
+type lockoutMock struct {
+	userRepo repoMocks.UserRepository
+	store    lockout.Store
+}
+
+func setLockoutConfig() {
+	config.Config = &config.Cfg{}
+	config.Config.Lockout.MaxAttempts = 5
+	config.Config.Lockout.IPMaxAttempts = 8
+	config.Config.Lockout.Duration = 900
+	config.Config.Lockout.BackoffBase = 1
+	config.Config.Lockout.BackoffMax = 4
+	config.Config.Lockout.Window = 600
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	setLockoutConfig()
+
+	now := time.Now()
+	svc := service.NewLockoutService(&repoMocks.UserRepository{}, lockout.NewMemoryStore(), service.WithLockoutClock(func() time.Time { return now }))
+	ctx := context.TODO()
+
+	// every failure doubles the delay from 1 second, up to 4 seconds
+	for _, wantDelay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		err := svc.Fail(ctx, "User@Mail.com", "10.0.0.1")
+		assert.Equal(t, nil, err)
+
+		var lockoutErr *service.LockoutError
+		err = svc.Check(ctx, "user@mail.com", "10.0.0.2")
+		assert.Equal(t, true, errors.As(err, &lockoutErr))
+		assert.Equal(t, wantDelay, lockoutErr.RetryAfter)
+
+		now = now.Add(wantDelay)
+		assert.Equal(t, nil, svc.Check(ctx, "user@mail.com", "10.0.0.2"))
+	}
+
+	// the fifth failure locks the email out
+	var lockoutErr *service.LockoutError
+	err := svc.Fail(ctx, "user@mail.com", "10.0.0.1")
+	assert.Equal(t, true, errors.As(err, &lockoutErr))
+	assert.Equal(t, 900*time.Second, lockoutErr.RetryAfter)
+	assert.Equal(t, "too many failed login attempts, try again in 900 seconds", lockoutErr.Error())
+	assert.Equal(t, int64(900), lockoutErr.Notice().RetryAfter)
+
+	// other emails from the same ip are not locked yet
+	assert.Equal(t, nil, svc.Check(ctx, "other@mail.com", "10.0.0.1"))
+
+	now = now.Add(900 * time.Second)
+	assert.Equal(t, nil, svc.Check(ctx, "user@mail.com", "10.0.0.1"))
+}
+
+func TestLockoutWindow(t *testing.T) {
+	setLockoutConfig()
+
+	now := time.Now()
+	svc := service.NewLockoutService(&repoMocks.UserRepository{}, lockout.NewMemoryStore(), service.WithLockoutClock(func() time.Time { return now }))
+	ctx := context.TODO()
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, nil, svc.Fail(ctx, "user@mail.com", ""))
+	}
+
+	// failures older than the window are forgotten, the delay starts over
+	now = now.Add(601 * time.Second)
+	assert.Equal(t, nil, svc.Fail(ctx, "user@mail.com", ""))
+
+	var lockoutErr *service.LockoutError
+	err := svc.Check(ctx, "user@mail.com", "")
+	assert.Equal(t, true, errors.As(err, &lockoutErr))
+	assert.Equal(t, time.Second, lockoutErr.RetryAfter)
+}
+
+func TestLockoutIP(t *testing.T) {
+	setLockoutConfig()
+	config.Config.Lockout.BackoffBase = 0
+
+	now := time.Now()
+	svc := service.NewLockoutService(&repoMocks.UserRepository{}, lockout.NewMemoryStore(), service.WithLockoutClock(func() time.Time { return now }))
+	ctx := context.TODO()
+
+	var err error
+	for i := 0; i < 8; i++ {
+		err = svc.Fail(ctx, "user"+string(rune('a'+i))+"@mail.com", "10.0.0.1")
+	}
+
+	var lockoutErr *service.LockoutError
+	assert.Equal(t, true, errors.As(err, &lockoutErr))
+
+	err = svc.Check(ctx, "new@mail.com", "10.0.0.1")
+	assert.Equal(t, true, errors.As(err, &lockoutErr))
+	assert.Equal(t, nil, svc.Check(ctx, "new@mail.com", "10.0.0.2"))
+}
+
+func TestLockoutSucceed(t *testing.T) {
+	setLockoutConfig()
+
+	now := time.Now()
+	svc := service.NewLockoutService(&repoMocks.UserRepository{}, lockout.NewMemoryStore(), service.WithLockoutClock(func() time.Time { return now }))
+	ctx := context.TODO()
+
+	assert.Equal(t, nil, svc.Fail(ctx, "user@mail.com", "10.0.0.1"))
+	assert.Equal(t, nil, svc.Succeed(ctx, "user@mail.com"))
+	assert.Equal(t, nil, svc.Check(ctx, "user@mail.com", "10.0.0.1"))
+}
+
+func TestLockoutUnlock(t *testing.T) {
+	user := model.User{
+		ID:    1,
+		Email: "user@mail.com",
+	}
+
+	testCases := []struct {
+		name     string
+		ip       string
+		mockFunc func(mock *lockoutMock)
+		wantErr  error
+	}{
+		{
+			name: "success unlock user",
+			mockFunc: func(listMock *lockoutMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+			},
+		},
+		{
+			name: "success unlock user and ip",
+			ip:   "10.0.0.1",
+			mockFunc: func(listMock *lockoutMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+			},
+		},
+		{
+			name: "failed unlock user - user not found",
+			mockFunc: func(listMock *lockoutMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			setLockoutConfig()
+			config.Config.Lockout.MaxAttempts = 1
+			config.Config.Lockout.IPMaxAttempts = 1
+
+			listMock := lockoutMock{
+				userRepo: repoMocks.UserRepository{},
+				store:    lockout.NewMemoryStore(),
+			}
+			tc.mockFunc(&listMock)
+
+			svc := service.NewLockoutService(&listMock.userRepo, listMock.store)
+			_ = svc.Fail(context.TODO(), user.Email, "10.0.0.1")
+
+			err := svc.Unlock(context.TODO(), user.ID, tc.ip)
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+
+			// the ip stays locked unless it was given
+			unlocked := svc.Check(context.TODO(), user.Email, "10.0.0.2") == nil
+			assert.Equal(t, tc.wantErr == nil, unlocked)
+			ipUnlocked := svc.Check(context.TODO(), "other@mail.com", "10.0.0.1") == nil
+			assert.Equal(t, tc.ip != "", ipUnlocked)
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/pkg/keyset"
+	"github.com/si-bas/go-rest-boilerplate/pkg/oidc"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"gorm.io/gorm"
+)
+
+const (
+	stubClientId     = "client"
+	stubClientSecret = "client secret"
+	stubRedirectUrl  = "http://localhost:3000/callback"
+)
+
+type oidcMock struct {
+	userRepo         repoMocks.UserRepository
+	userIdentityRepo repoMocks.UserIdentityRepository
+	oidcStateRepo    repoMocks.OidcStateRepository
+}
+
+// stubIdp is an identity provider serving the discovery, the keys and the token endpoint,
+// the codes are issued by authorize as if the user logged in
+type stubIdp struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]stubGrant
+}
+
+type stubGrant struct {
+	codeChallenge string
+	idToken       string
+}
+
+func newStubIdp(t *testing.T) *stubIdp {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &stubIdp{
+		key:   key,
+		codes: map[string]stubGrant{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(keyset.JWKS{Keys: []keyset.JWK{{
+			Kty: "RSA",
+			Kid: "idp",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		// the credentials are form encoded before the basic scheme
+		user, password, _ := r.BasicAuth()
+		user, _ = url.QueryUnescape(user)
+		password, _ = url.QueryUnescape(password)
+		if user != stubClientId || password != stubClientSecret || r.PostFormValue("client_id") != stubClientId {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		idp.mu.Lock()
+		grant, ok := idp.codes[r.PostFormValue("code")]
+		delete(idp.codes, r.PostFormValue("code"))
+		idp.mu.Unlock()
+
+		if !ok || r.PostFormValue("redirect_uri") != stubRedirectUrl ||
+			oidc.CodeChallenge(r.PostFormValue("code_verifier")) != grant.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     grant.idToken,
+		})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// authorize stands for the user logging in at the provider, the claims can be changed before the id token is signed
+func (idp *stubIdp) authorize(t *testing.T, authorizationUrl string, signingKey *rsa.PrivateKey, edit func(jwt.MapClaims)) string {
+	u, err := url.Parse(authorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+
+	assert.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, stubClientId, query.Get("client_id"))
+	assert.Equal(t, stubRedirectUrl, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	claims := jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            stubClientId,
+		"sub":            "subject",
+		"email":          "user@mail.com",
+		"email_verified": true,
+		"name":           "User",
+		"nonce":          query.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+	}
+	if edit != nil {
+		edit(claims)
+	}
+
+	if signingKey == nil {
+		signingKey = idp.key
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp"
+	idToken, err := token.SignedString(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := "code-" + query.Get("state")
+	idp.mu.Lock()
+	idp.codes[code] = stubGrant{codeChallenge: query.Get("code_challenge"), idToken: idToken}
+	idp.mu.Unlock()
+
+	return code
+}
+
+func oidcConfig(issuer string, autoRegister bool) {
+	config.Config = &config.Cfg{}
+	config.Config.Oidc.Providers = []config.OidcProvider{{
+		Name:         "stub",
+		Issuer:       issuer,
+		ClientId:     stubClientId,
+		ClientSecret: stubClientSecret,
+		RedirectUrl:  stubRedirectUrl,
+		Scopes:       []string{"email"},
+		AutoRegister: autoRegister,
+	}}
+}
+
+// mockStates keeps the states the service inserts, a state is only found until it is deleted
+func (m *oidcMock) mockStates() {
+	states := map[string]*model.OidcState{}
+	var mu sync.Mutex
+
+	m.oidcStateRepo.On("Insert", mock.Anything, mock.Anything).Return(func(ctx context.Context, oidcState *model.OidcState) error {
+		mu.Lock()
+		defer mu.Unlock()
+		oidcState.ID = uint32(len(states) + 1)
+		states[oidcState.StateHash] = oidcState
+		return nil
+	})
+	m.oidcStateRepo.On("FindByStateHash", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, stateHash string) *model.OidcState {
+			mu.Lock()
+			defer mu.Unlock()
+			return states[stateHash]
+		},
+		func(ctx context.Context, stateHash string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if states[stateHash] == nil {
+				return gorm.ErrRecordNotFound
+			}
+			return nil
+		})
+	m.oidcStateRepo.On("Delete", mock.Anything, mock.Anything).Return(func(ctx context.Context, id uint32) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for stateHash, oidcState := range states {
+			if oidcState.ID == id {
+				delete(states, stateHash)
+				return nil
+			}
+		}
+		return gorm.ErrRecordNotFound
+	})
+}
+
+func TestOidcLogin(t *testing.T) {
+	idp := newStubIdp(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifiedAt := time.Now()
+	linkedUser := &model.User{ID: 1, Email: "linked@mail.com", EmailVerifiedAt: &verifiedAt}
+	verifiedUser := &model.User{ID: 2, Email: "user@mail.com", EmailVerifiedAt: &verifiedAt}
+	unverifiedUser := &model.User{ID: 3, Email: "user@mail.com"}
+
+	testCases := []struct {
+		name         string
+		autoRegister bool
+		identity     *model.UserIdentity
+		userByEmail  *model.User
+		signingKey   *rsa.PrivateKey
+		edit         func(jwt.MapClaims)
+		userId       uint32
+		linked       bool
+		registered   bool
+		err          error
+	}{
+		{
+			name:     "identity already linked",
+			identity: &model.UserIdentity{UserID: 1, Provider: "stub", Subject: "subject"},
+			userId:   1,
+		},
+		{
+			name:        "new identity is linked to the user of the verified email",
+			userByEmail: verifiedUser,
+			userId:      2,
+			linked:      true,
+		},
+		{
+			name:         "new identity registers a user when the provider allows it",
+			autoRegister: true,
+			linked:       true,
+			registered:   true,
+		},
+		{
+			name: "unknown email without registration",
+			err:  service.ErrOidcUserNotFound,
+		},
+		{
+			name:        "user whose email is not verified is not linked",
+			userByEmail: unverifiedUser,
+			err:         service.ErrEmailNotVerified,
+		},
+		{
+			name: "email not verified by the provider",
+			edit: func(claims jwt.MapClaims) { claims["email_verified"] = false },
+			err:  service.ErrOidcEmailNotVerified,
+		},
+		{
+			name: "nonce of another login",
+			edit: func(claims jwt.MapClaims) { claims["nonce"] = "other" },
+			err:  service.ErrInvalidOidcLogin,
+		},
+		{
+			name: "token for another client",
+			edit: func(claims jwt.MapClaims) { claims["aud"] = "other" },
+			err:  service.ErrInvalidOidcLogin,
+		},
+		{
+			name: "token of another issuer",
+			edit: func(claims jwt.MapClaims) { claims["iss"] = "https://other.example.com" },
+			err:  service.ErrInvalidOidcLogin,
+		},
+		{
+			name: "expired token",
+			edit: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			err:  service.ErrInvalidOidcLogin,
+		},
+		{
+			name:       "token not signed by the provider",
+			signingKey: otherKey,
+			err:        service.ErrInvalidOidcLogin,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oidcConfig(idp.server.URL, tc.autoRegister)
+
+			listMock := oidcMock{}
+			listMock.mockStates()
+			if tc.identity != nil {
+				listMock.userIdentityRepo.On("FindByProviderAndSubject", mock.Anything, "stub", "subject").Return(tc.identity, nil)
+			} else {
+				listMock.userIdentityRepo.On("FindByProviderAndSubject", mock.Anything, "stub", "subject").Return(nil, gorm.ErrRecordNotFound)
+			}
+			listMock.userIdentityRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+			listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(linkedUser, nil)
+			if tc.userByEmail != nil {
+				listMock.userRepo.On("FindByEmail", mock.Anything, "user@mail.com").Return(tc.userByEmail, nil)
+			} else {
+				listMock.userRepo.On("FindByEmail", mock.Anything, "user@mail.com").Return(nil, gorm.ErrRecordNotFound)
+			}
+			listMock.userRepo.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*model.User).ID = 4
+			}).Return(nil)
+
+			svc := service.NewOidcService(&listMock.userRepo, &listMock.userIdentityRepo, &listMock.oidcStateRepo)
+
+			authorization, err := svc.Authorize(context.TODO(), "stub")
+			assert.Equal(t, err, nil)
+
+			code := idp.authorize(t, authorization.AuthorizationUrl, tc.signingKey, tc.edit)
+			user, err := svc.Login(context.TODO(), "stub", model.OidcCallbackRequest{Code: code, State: authorization.State})
+			if tc.err == service.ErrInvalidOidcLogin {
+				assert.Equal(t, tc.err, custErr.Type(err))
+			} else {
+				assert.Equal(t, tc.err, err)
+			}
+			if tc.err != nil {
+				listMock.userIdentityRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+				return
+			}
+
+			if tc.registered {
+				listMock.userRepo.AssertCalled(t, "Insert", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Email == "user@mail.com" && user.Name == "User" && user.EmailVerifiedAt != nil && user.Password != ""
+				}))
+				assert.Equal(t, uint32(4), user.ID)
+			} else {
+				listMock.userRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+				assert.Equal(t, tc.userId, user.ID)
+			}
+
+			if tc.linked {
+				listMock.userIdentityRepo.AssertCalled(t, "Insert", mock.Anything, mock.MatchedBy(func(identity *model.UserIdentity) bool {
+					return identity.UserID == user.ID && identity.Provider == "stub" && identity.Subject == "subject" && identity.Email == "user@mail.com"
+				}))
+			} else {
+				listMock.userIdentityRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestOidcState(t *testing.T) {
+	idp := newStubIdp(t)
+	oidcConfig(idp.server.URL, false)
+	config.Config.Oidc.StateExpiresIn = 60
+
+	verifiedAt := time.Now()
+	listMock := oidcMock{}
+	listMock.mockStates()
+	listMock.userIdentityRepo.On("FindByProviderAndSubject", mock.Anything, "stub", "subject").Return(&model.UserIdentity{UserID: 1}, nil)
+	listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1, EmailVerifiedAt: &verifiedAt}, nil)
+
+	now := time.Now()
+	svc := service.NewOidcService(&listMock.userRepo, &listMock.userIdentityRepo, &listMock.oidcStateRepo, service.WithOidcClock(func() time.Time { return now }))
+
+	_, err := svc.Authorize(context.TODO(), "unknown")
+	assert.Equal(t, service.ErrOidcProviderNotFound, err)
+
+	// the state is single use
+	authorization, err := svc.Authorize(context.TODO(), "stub")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, int64(60), authorization.ExpiresIn)
+
+	code := idp.authorize(t, authorization.AuthorizationUrl, nil, nil)
+	user, err := svc.Login(context.TODO(), "stub", model.OidcCallbackRequest{Code: code, State: authorization.State})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, uint32(1), user.ID)
+
+	_, err = svc.Login(context.TODO(), "stub", model.OidcCallbackRequest{Code: code, State: authorization.State})
+	assert.Equal(t, service.ErrInvalidOidcState, err)
+
+	// an unknown state never reaches the provider
+	_, err = svc.Login(context.TODO(), "stub", model.OidcCallbackRequest{Code: "code", State: "unknown"})
+	assert.Equal(t, service.ErrInvalidOidcState, err)
+
+	// a code can only be redeemed with the verifier of the login it was issued for
+	first, err := svc.Authorize(context.TODO(), "stub")
+	assert.Equal(t, err, nil)
+	second, err := svc.Authorize(context.TODO(), "stub")
+	assert.Equal(t, err, nil)
+
+	code = idp.authorize(t, first.AuthorizationUrl, nil, nil)
+	_, err = svc.Login(context.TODO(), "stub", model.OidcCallbackRequest{Code: code, State: second.State})
+	assert.Equal(t, service.ErrInvalidOidcLogin, custErr.Type(err))
+
+	// an expired state is consumed and rejected
+	expired, err := svc.Authorize(context.TODO(), "stub")
+	assert.Equal(t, err, nil)
+
+	code = idp.authorize(t, expired.AuthorizationUrl, nil, nil)
+	now = now.Add(61 * time.Second)
+	_, err = svc.Login(context.TODO(), "stub", model.OidcCallbackRequest{Code: code, State: expired.State})
+	assert.Equal(t, service.ErrInvalidOidcState, err)
+}
@@ -0,0 +1,264 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/pkg/denylist"
+	"github.com/si-bas/go-rest-boilerplate/pkg/notifier"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"gorm.io/gorm"
+)
+
+type passwordMock struct {
+	userRepo          repoMocks.UserRepository
+	passwordResetRepo repoMocks.PasswordResetRepository
+	refreshTokenRepo  repoMocks.RefreshTokenRepository
+	sessionRepo       repoMocks.SessionRepository
+	txManager         repoMocks.TxManager
+	notifier          recordNotifier
+}
+
+// recordNotifier keeps the messages instead of sending them, err is returned as a failed delivery
+type recordNotifier struct {
+	messages []notifier.Message
+	err      error
+}
+
+func (n *recordNotifier) Send(ctx context.Context, message notifier.Message) error {
+	if n.err != nil {
+		return n.err
+	}
+
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func newPasswordService(t *testing.T, listMock *passwordMock) service.PasswordService {
+	authService := service.NewAuthService(&listMock.userRepo, &repoMocks.RoleRepository{}, &listMock.refreshTokenRepo, &listMock.sessionRepo, denylist.NewMemoryDenylist(), newKeySet(t))
+	runInTransaction(&listMock.txManager)
+	return service.NewPasswordService(&listMock.userRepo, &listMock.passwordResetRepo, authService, &listMock.notifier, &listMock.txManager)
+}
+
+func TestPasswordChange(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.ExpiresIn = 900
+
+	testCases := []struct {
+		name            string
+		currentPassword string
+		mockFunc        func(mock *passwordMock, user *model.User)
+		wantErr         error
+	}{
+		{
+			name:            "success change password",
+			currentPassword: "admin",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.MatchedBy(func(updated *model.User) bool {
+					return updated.VerifyPassword("new-password") == nil
+				})).Return(nil)
+				listMock.sessionRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+				listMock.refreshTokenRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+			},
+		},
+		{
+			name:            "failed change password - current password is invalid",
+			currentPassword: "wrong",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+			},
+			wantErr: service.ErrCurrentPasswordInvalid,
+		},
+		{
+			name:            "failed change password - user not found",
+			currentPassword: "admin",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			user := model.User{
+				ID:       1,
+				Email:    "user@mail.com",
+				Password: "$2a$10$6ItIWM3fUWVmY1GzGU4pzOGUtUVXUbbkHVA1F9fEvlkJchvHU7XF2",
+			}
+
+			listMock := passwordMock{}
+			tc.mockFunc(&listMock, &user)
+
+			svc := newPasswordService(t, &listMock)
+			err := svc.ChangePassword(context.TODO(), 1, model.ChangePasswordRequest{
+				CurrentPassword: tc.currentPassword,
+				NewPassword:     "new-password",
+			})
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPasswordForgot(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Password.ResetExpiresIn = 1800
+	config.Config.Password.ResetUrl = "https://app.example.com/reset-password"
+
+	user := model.User{
+		ID:    1,
+		Name:  "user",
+		Email: "user@mail.com",
+	}
+
+	t.Run("success forgot password - token is sent and stored hashed", func(t *testing.T) {
+		var stored *model.PasswordReset
+
+		listMock := passwordMock{}
+		listMock.userRepo.On("FindByEmail", mock.Anything, user.Email).Return(&user, nil)
+		listMock.passwordResetRepo.On("DeleteByUserId", mock.Anything, uint32(1)).Return(nil)
+		listMock.passwordResetRepo.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*model.PasswordReset)
+		}).Return(nil)
+
+		svc := newPasswordService(t, &listMock)
+		err := svc.ForgotPassword(context.TODO(), user.Email)
+
+		assert.Equal(t, nil, err)
+		listMock.passwordResetRepo.AssertExpectations(t)
+		assert.Equal(t, 1, len(listMock.notifier.messages))
+		assert.Equal(t, user.Email, listMock.notifier.messages[0].To)
+		assert.Equal(t, true, strings.Contains(listMock.notifier.messages[0].Body, "https://app.example.com/reset-password?token="))
+		assert.Equal(t, false, strings.Contains(listMock.notifier.messages[0].Body, stored.TokenHash))
+		assert.Equal(t, 64, len(stored.TokenHash))
+		assert.Equal(t, true, stored.ExpiresAt.After(time.Now().Add(29*time.Minute)))
+	})
+
+	t.Run("success forgot password - unknown email sends nothing", func(t *testing.T) {
+		listMock := passwordMock{}
+		listMock.userRepo.On("FindByEmail", mock.Anything, "unknown@mail.com").Return(nil, gorm.ErrRecordNotFound)
+
+		svc := newPasswordService(t, &listMock)
+		err := svc.ForgotPassword(context.TODO(), "unknown@mail.com")
+
+		assert.Equal(t, nil, err)
+		listMock.passwordResetRepo.AssertExpectations(t)
+		assert.Equal(t, 0, len(listMock.notifier.messages))
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Jwt.ExpiresIn = 900
+
+	usedAt := time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name     string
+		mockFunc func(mock *passwordMock, user *model.User)
+		wantErr  error
+	}{
+		{
+			name: "success reset password",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.passwordResetRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.PasswordReset{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				listMock.passwordResetRepo.On("MarkUsed", mock.Anything, uint32(1)).Return(nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.MatchedBy(func(updated *model.User) bool {
+					return updated.VerifyPassword("new-password") == nil
+				})).Return(nil)
+				listMock.sessionRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+				listMock.refreshTokenRepo.On("RevokeByUserId", mock.Anything, uint32(1)).Return(nil)
+			},
+		},
+		{
+			name: "failed reset password - unknown token",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.passwordResetRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrInvalidResetToken,
+		},
+		{
+			name: "failed reset password - expired token",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.passwordResetRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.PasswordReset{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(-time.Second),
+				}, nil)
+			},
+			wantErr: service.ErrInvalidResetToken,
+		},
+		{
+			name: "failed reset password - used token",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.passwordResetRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.PasswordReset{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt,
+				}, nil)
+			},
+			wantErr: service.ErrInvalidResetToken,
+		},
+		{
+			name: "failed reset password - token used by a concurrent reset",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.passwordResetRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.PasswordReset{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				listMock.passwordResetRepo.On("MarkUsed", mock.Anything, uint32(1)).Return(gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrInvalidResetToken,
+		},
+		{
+			name: "failed reset password - password not updated, the token is not used up",
+			mockFunc: func(listMock *passwordMock, user *model.User) {
+				listMock.passwordResetRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.PasswordReset{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				listMock.passwordResetRepo.On("MarkUsed", mock.Anything, uint32(1)).Return(nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.Anything).Return(gorm.ErrInvalidDB)
+				listMock.txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+					// the error of the function rolls back the use of the token
+					return fn(ctx)
+				}).Once()
+			},
+			wantErr: gorm.ErrInvalidDB,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			user := model.User{ID: 1, Email: "user@mail.com"}
+
+			listMock := passwordMock{}
+			tc.mockFunc(&listMock, &user)
+
+			svc := newPasswordService(t, &listMock)
+			err := svc.ResetPassword(context.TODO(), model.ResetPasswordRequest{
+				Token:       "token",
+				NewPassword: "new-password",
+			})
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.passwordResetRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+			listMock.txManager.AssertExpectations(t)
+			listMock.sessionRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"gorm.io/gorm"
+)
+
+type registrationMock struct {
+	userRepo              repoMocks.UserRepository
+	emailVerificationRepo repoMocks.EmailVerificationRepository
+	txManager             repoMocks.TxManager
+	notifier              recordNotifier
+}
+
+func newRegistrationService(listMock *registrationMock) service.RegistrationService {
+	runInTransaction(&listMock.txManager)
+	return service.NewRegistrationService(&listMock.userRepo, &listMock.emailVerificationRepo, &listMock.notifier, &listMock.txManager)
+}
+
+func TestRegistrationRegister(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.Registration.VerifyUrl = "https://app.example.com/verify-email"
+
+	payload := model.RegisterRequest{
+		Name:     "new user",
+		Email:    "newuser@mail.com",
+		Password: "secret",
+	}
+
+	testCases := []struct {
+		name         string
+		mockFunc     func(mock *registrationMock)
+		wantErr      error
+		wantMessages int
+	}{
+		{
+			name: "success register - user is unverified and the token is sent",
+			mockFunc: func(listMock *registrationMock) {
+				listMock.userRepo.On("Insert", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					user.ID = 1
+					return user.Email == payload.Email && user.EmailVerifiedAt == nil
+				})).Return(nil)
+				listMock.emailVerificationRepo.On("DeleteByUserId", mock.Anything, uint32(1)).Return(nil)
+				listMock.emailVerificationRepo.On("Insert", mock.Anything, mock.MatchedBy(func(emailVerification *model.EmailVerification) bool {
+					return emailVerification.UserID == 1 && len(emailVerification.TokenHash) == 64
+				})).Return(nil)
+			},
+			wantMessages: 1,
+		},
+		{
+			name: "success register - failed delivery is not an error",
+			mockFunc: func(listMock *registrationMock) {
+				listMock.userRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+				listMock.emailVerificationRepo.On("DeleteByUserId", mock.Anything, mock.Anything).Return(nil)
+				listMock.emailVerificationRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+				listMock.notifier.err = errors.New("smtp is down")
+			},
+		},
+		{
+			name: "failed register - email already used",
+			mockFunc: func(listMock *registrationMock) {
+				listMock.userRepo.On("Insert", mock.Anything, mock.Anything).Return(errUserConflict)
+			},
+			wantErr: service.ErrEmailAlreadyUsed,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := registrationMock{}
+			tc.mockFunc(&listMock)
+
+			svc := newRegistrationService(&listMock)
+			user, err := svc.Register(context.TODO(), payload)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.emailVerificationRepo.AssertExpectations(t)
+			assert.Equal(t, tc.wantMessages, len(listMock.notifier.messages))
+
+			if err == nil {
+				assert.Equal(t, (*time.Time)(nil), user.EmailVerifiedAt)
+			}
+			if tc.wantMessages > 0 {
+				assert.Equal(t, true, strings.Contains(listMock.notifier.messages[0].Body, "https://app.example.com/verify-email?token="))
+			}
+		})
+	}
+}
+
+func TestRegistrationVerifyEmail(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name     string
+		mockFunc func(mock *registrationMock, user *model.User)
+		wantErr  error
+	}{
+		{
+			name: "success verify email",
+			mockFunc: func(listMock *registrationMock, user *model.User) {
+				listMock.emailVerificationRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.EmailVerification{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				listMock.emailVerificationRepo.On("MarkUsed", mock.Anything, uint32(1)).Return(nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.MatchedBy(func(updated *model.User) bool {
+					return updated.EmailVerifiedAt != nil
+				})).Return(nil)
+			},
+		},
+		{
+			name: "success verify email - already verified",
+			mockFunc: func(listMock *registrationMock, user *model.User) {
+				verifiedAt := time.Now().Add(-time.Hour)
+				user.EmailVerifiedAt = &verifiedAt
+				listMock.emailVerificationRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.EmailVerification{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				listMock.emailVerificationRepo.On("MarkUsed", mock.Anything, uint32(1)).Return(nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+			},
+		},
+		{
+			name: "failed verify email - unknown token",
+			mockFunc: func(listMock *registrationMock, user *model.User) {
+				listMock.emailVerificationRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrInvalidVerificationToken,
+		},
+		{
+			name: "failed verify email - expired token",
+			mockFunc: func(listMock *registrationMock, user *model.User) {
+				listMock.emailVerificationRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.EmailVerification{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(-time.Second),
+				}, nil)
+			},
+			wantErr: service.ErrInvalidVerificationToken,
+		},
+		{
+			name: "failed verify email - used token",
+			mockFunc: func(listMock *registrationMock, user *model.User) {
+				listMock.emailVerificationRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.EmailVerification{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt,
+				}, nil)
+			},
+			wantErr: service.ErrInvalidVerificationToken,
+		},
+		{
+			name: "failed verify email - email not verified, the token is not used up",
+			mockFunc: func(listMock *registrationMock, user *model.User) {
+				listMock.emailVerificationRepo.On("FindByTokenHash", mock.Anything, mock.Anything).Return(&model.EmailVerification{
+					ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				listMock.emailVerificationRepo.On("MarkUsed", mock.Anything, uint32(1)).Return(nil)
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(user, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.Anything).Return(gorm.ErrInvalidDB)
+				listMock.txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+					// the error of the function rolls back the use of the token
+					return fn(ctx)
+				}).Once()
+			},
+			wantErr: gorm.ErrInvalidDB,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			user := model.User{ID: 1, Email: "newuser@mail.com"}
+
+			listMock := registrationMock{}
+			tc.mockFunc(&listMock, &user)
+
+			svc := newRegistrationService(&listMock)
+			err := svc.VerifyEmail(context.TODO(), model.VerifyEmailRequest{Token: "token"})
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.emailVerificationRepo.AssertExpectations(t)
+			listMock.txManager.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRegistrationResendVerification(t *testing.T) {
+	config.Config = &config.Cfg{}
+
+	verifiedAt := time.Now()
+
+	testCases := []struct {
+		name         string
+		mockFunc     func(mock *registrationMock)
+		wantMessages int
+	}{
+		{
+			name: "unverified user gets a new token",
+			mockFunc: func(listMock *registrationMock) {
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(&model.User{ID: 1, Email: "newuser@mail.com"}, nil)
+				listMock.emailVerificationRepo.On("DeleteByUserId", mock.Anything, uint32(1)).Return(nil)
+				listMock.emailVerificationRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+			},
+			wantMessages: 1,
+		},
+		{
+			name: "verified user gets nothing",
+			mockFunc: func(listMock *registrationMock) {
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(&model.User{ID: 1, EmailVerifiedAt: &verifiedAt}, nil)
+			},
+		},
+		{
+			name: "unknown email gets nothing",
+			mockFunc: func(listMock *registrationMock) {
+				listMock.userRepo.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := registrationMock{}
+			tc.mockFunc(&listMock)
+
+			svc := newRegistrationService(&listMock)
+			err := svc.ResendVerification(context.TODO(), "newuser@mail.com")
+
+			assert.Equal(t, nil, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.emailVerificationRepo.AssertExpectations(t)
+			assert.Equal(t, tc.wantMessages, len(listMock.notifier.messages))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"gorm.io/gorm"
+)
+
+type roleMock struct {
+	userRepo       repoMocks.UserRepository
+	roleRepo       repoMocks.RoleRepository
+	permissionRepo repoMocks.PermissionRepository
+}
+
+func TestRoleSetUserRoles(t *testing.T) {
+	adminRole := model.Role{ID: 1, Name: "admin"}
+
+	testCases := []struct {
+		name     string
+		roles    []string
+		mockFunc func(mock *roleMock)
+		wantErr  error
+	}{
+		{
+			name:  "success set user roles",
+			roles: []string{"admin", "admin"},
+			mockFunc: func(listMock *roleMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, nil)
+				listMock.roleRepo.On("GetByNames", mock.Anything, []string{"admin", "admin"}).Return([]model.Role{adminRole}, nil)
+				listMock.roleRepo.On("SetUserRoles", mock.Anything, uint32(1), []uint32{1}).Return(nil)
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{adminRole}, nil)
+			},
+		},
+		{
+			name:  "success remove every user role",
+			roles: []string{},
+			mockFunc: func(listMock *roleMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, nil)
+				listMock.roleRepo.On("SetUserRoles", mock.Anything, uint32(1), []uint32(nil)).Return(nil)
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{}, nil)
+			},
+		},
+		{
+			name:  "failed set user roles - unknown role",
+			roles: []string{"admin", "unknown"},
+			mockFunc: func(listMock *roleMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, nil)
+				listMock.roleRepo.On("GetByNames", mock.Anything, []string{"admin", "unknown"}).Return([]model.Role{adminRole}, nil)
+			},
+			wantErr: service.ErrRoleNotFound,
+		},
+		{
+			name:  "failed set user roles - user not found",
+			roles: []string{"admin"},
+			mockFunc: func(listMock *roleMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := roleMock{
+				userRepo:       repoMocks.UserRepository{},
+				roleRepo:       repoMocks.RoleRepository{},
+				permissionRepo: repoMocks.PermissionRepository{},
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			svc := service.NewRoleService(&listMock.userRepo, &listMock.roleRepo, &listMock.permissionRepo)
+			_, err := svc.SetUserRoles(context.TODO(), uint32(1), tc.roles)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRoleGetUserRoles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mockFunc func(mock *roleMock)
+		wantErr  error
+	}{
+		{
+			name: "success get user roles",
+			mockFunc: func(listMock *roleMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, nil)
+				listMock.roleRepo.On("GetByUserId", mock.Anything, uint32(1)).Return([]model.Role{{ID: 1, Name: "admin"}}, nil)
+			},
+		},
+		{
+			name: "failed get user roles - user not found",
+			mockFunc: func(listMock *roleMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := roleMock{
+				userRepo: repoMocks.UserRepository{},
+				roleRepo: repoMocks.RoleRepository{},
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			svc := service.NewRoleService(&listMock.userRepo, &listMock.roleRepo, &listMock.permissionRepo)
+			result, err := svc.GetUserRoles(context.TODO(), uint32(1))
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+			listMock.roleRepo.AssertExpectations(t)
+
+			if err == nil {
+				assert.Equal(t, len(result), 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/stretchr/testify/mock"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"gorm.io/gorm"
+)
+
+type sessionMock struct {
+	sessionRepo      repoMocks.SessionRepository
+	refreshTokenRepo repoMocks.RefreshTokenRepository
+}
+
+func TestSessionList(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	listMock := sessionMock{}
+	listMock.sessionRepo.On("GetActiveByUserId", mock.Anything, uint32(1), now).Return([]model.Session{{ID: "current"}, {ID: "other"}}, nil)
+
+	svc := service.NewSessionService(&listMock.sessionRepo, &listMock.refreshTokenRepo, service.WithSessionClock(func() time.Time { return now }))
+	sessions, err := svc.List(context.TODO(), 1, "current")
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sessions), 2)
+	assert.Equal(t, sessions[0].Current, true)
+	assert.Equal(t, sessions[1].Current, false)
+	listMock.sessionRepo.AssertExpectations(t)
+}
+
+func TestSessionRevoke(t *testing.T) {
+	revokedAt := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name     string
+		mockFunc func(listMock *sessionMock)
+		wantErr  error
+	}{
+		{
+			name: "success revoke session",
+			mockFunc: func(listMock *sessionMock) {
+				listMock.sessionRepo.On("FindByUserIdAndId", mock.Anything, uint32(1), "session").Return(&model.Session{ID: "session", UserID: 1}, nil)
+				listMock.sessionRepo.On("Revoke", mock.Anything, "session").Return(nil)
+				listMock.refreshTokenRepo.On("RevokeFamily", mock.Anything, "session").Return(nil)
+			},
+		},
+		{
+			name: "failed revoke session - session of another user",
+			mockFunc: func(listMock *sessionMock) {
+				listMock.sessionRepo.On("FindByUserIdAndId", mock.Anything, uint32(1), "session").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrSessionNotFound,
+		},
+		{
+			name: "failed revoke session - already revoked",
+			mockFunc: func(listMock *sessionMock) {
+				listMock.sessionRepo.On("FindByUserIdAndId", mock.Anything, uint32(1), "session").Return(&model.Session{ID: "session", UserID: 1, RevokedAt: &revokedAt}, nil)
+			},
+			wantErr: service.ErrSessionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := sessionMock{}
+			tc.mockFunc(&listMock)
+
+			svc := service.NewSessionService(&listMock.sessionRepo, &listMock.refreshTokenRepo)
+			err := svc.Revoke(context.TODO(), 1, "session")
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.sessionRepo.AssertExpectations(t)
+			listMock.refreshTokenRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionRevokeOthers(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	listMock := sessionMock{}
+	listMock.sessionRepo.On("GetActiveByUserId", mock.Anything, uint32(1), now).Return([]model.Session{{ID: "current"}, {ID: "other"}, {ID: "concurrent"}}, nil)
+	listMock.sessionRepo.On("Revoke", mock.Anything, "other").Return(nil)
+	listMock.sessionRepo.On("Revoke", mock.Anything, "concurrent").Return(gorm.ErrRecordNotFound)
+	listMock.refreshTokenRepo.On("RevokeFamily", mock.Anything, "other").Return(nil)
+	listMock.refreshTokenRepo.On("RevokeFamily", mock.Anything, "concurrent").Return(nil)
+
+	svc := service.NewSessionService(&listMock.sessionRepo, &listMock.refreshTokenRepo, service.WithSessionClock(func() time.Time { return now }))
+	err := svc.RevokeOthers(context.TODO(), 1, "current")
+
+	assert.Equal(t, err, nil)
+	listMock.sessionRepo.AssertExpectations(t)
+	listMock.refreshTokenRepo.AssertExpectations(t)
+	listMock.sessionRepo.AssertNotCalled(t, "Revoke", mock.Anything, "current")
+}
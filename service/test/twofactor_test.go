@@ -0,0 +1,238 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
+	"github.com/si-bas/go-rest-boilerplate/pkg/totp"
+	"github.com/si-bas/go-rest-boilerplate/service"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type twoFactorMock struct {
+	userRepo      repoMocks.UserRepository
+	twoFactorRepo repoMocks.TwoFactorRepository
+	txManager     repoMocks.TxManager
+}
+
+// rfcSecret is the base32 of the seed of the RFC 6238 test vectors
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCode(t *testing.T) {
+	// the RFC lists 8 digits, a 6 digit code is the same value modulo 10^6
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(tc.unix, 0)))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, tc.code, code)
+	}
+
+	now := time.Unix(1111111111, 0)
+	previous, _ := totp.Code(rfcSecret, totp.Step(now)-1)
+
+	step, ok := totp.Validate(rfcSecret, previous, now, 1)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	_, ok = totp.Validate(rfcSecret, previous, now, 0)
+	assert.Equal(t, false, ok)
+
+	uri := totp.URI("My App", "user@mail.com", rfcSecret)
+	assert.Equal(t, true, strings.HasPrefix(uri, "otpauth://totp/My%20App:user@mail.com?"))
+	assert.Equal(t, true, strings.Contains(uri, "secret="+rfcSecret))
+}
+
+func TestTwoFactorEnrollAndConfirm(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.App.Name = "app"
+	config.Config.TwoFactor.Skew = 1
+
+	now := time.Unix(1700000000, 0)
+	user := model.User{ID: 1, Email: "user@mail.com"}
+
+	listMock := twoFactorMock{}
+	var pending *model.TwoFactor
+	listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&user, nil)
+	listMock.twoFactorRepo.On("FindByUserId", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound).Once()
+	listMock.twoFactorRepo.On("Save", mock.Anything, mock.MatchedBy(func(twoFactor *model.TwoFactor) bool {
+		return twoFactor.ConfirmedAt == nil
+	})).Run(func(args mock.Arguments) {
+		pending = args.Get(1).(*model.TwoFactor)
+	}).Return(nil).Once()
+
+	runInTransaction(&listMock.txManager)
+	svc := service.NewTwoFactorService(&listMock.userRepo, &listMock.twoFactorRepo, &listMock.txManager, service.WithTwoFactorClock(func() time.Time { return now }))
+
+	enrollment, err := svc.Enroll(context.TODO(), 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pending.Secret, enrollment.Secret)
+	assert.Equal(t, true, strings.HasPrefix(enrollment.OtpauthUri, "otpauth://totp/app:user@mail.com?"))
+
+	// a wrong code leaves 2FA disabled
+	listMock.twoFactorRepo.On("FindByUserId", mock.Anything, uint32(1)).Return(pending, nil)
+	_, err = svc.Confirm(context.TODO(), 1, "000000")
+	assert.Equal(t, service.ErrInvalidTwoFactorCode, err)
+
+	code, _ := totp.Code(pending.Secret, totp.Step(now))
+	listMock.twoFactorRepo.On("UseStep", mock.Anything, uint32(1), totp.Step(now)).Return(nil)
+	listMock.twoFactorRepo.On("Save", mock.Anything, mock.MatchedBy(func(twoFactor *model.TwoFactor) bool {
+		return twoFactor.ConfirmedAt != nil && twoFactor.LastUsedStep == totp.Step(now)
+	})).Return(nil).Once()
+	listMock.twoFactorRepo.On("ReplaceRecoveryCodes", mock.Anything, uint32(1), mock.MatchedBy(func(recoveryCodes []model.RecoveryCode) bool {
+		return len(recoveryCodes) == 10 && len(recoveryCodes[0].CodeHash) == 64
+	})).Return(nil)
+
+	recoveryCodes, err := svc.Confirm(context.TODO(), 1, code)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, 10, len(recoveryCodes.RecoveryCodes))
+	assert.Equal(t, 11, len(recoveryCodes.RecoveryCodes[0]))
+	listMock.twoFactorRepo.AssertExpectations(t)
+
+	// once enabled it cannot be enrolled nor confirmed again
+	_, err = svc.Enroll(context.TODO(), 1)
+	assert.Equal(t, service.ErrTwoFactorAlreadyEnabled, err)
+
+	_, err = svc.Confirm(context.TODO(), 1, code)
+	assert.Equal(t, service.ErrTwoFactorAlreadyEnabled, err)
+}
+
+func TestTwoFactorConfirmRecoveryCodesNotSaved(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.TwoFactor.Skew = 1
+
+	now := time.Unix(1700000000, 0)
+	twoFactor := &model.TwoFactor{UserID: 1, Secret: rfcSecret}
+	code, _ := totp.Code(rfcSecret, totp.Step(now))
+
+	listMock := twoFactorMock{}
+	inTransaction := false
+	listMock.txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		inTransaction = true
+		defer func() { inTransaction = false }()
+		return fn(ctx)
+	})
+	listMock.twoFactorRepo.On("FindByUserId", mock.Anything, uint32(1)).Return(twoFactor, nil)
+	listMock.twoFactorRepo.On("UseStep", mock.Anything, uint32(1), totp.Step(now)).Return(nil)
+	listMock.twoFactorRepo.On("Save", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.Equal(t, inTransaction, true)
+	}).Return(nil)
+	listMock.twoFactorRepo.On("ReplaceRecoveryCodes", mock.Anything, uint32(1), mock.Anything).Run(func(args mock.Arguments) {
+		assert.Equal(t, inTransaction, true)
+	}).Return(gorm.ErrInvalidDB)
+
+	svc := service.NewTwoFactorService(&listMock.userRepo, &listMock.twoFactorRepo, &listMock.txManager, service.WithTwoFactorClock(func() time.Time { return now }))
+
+	// the confirmation is rolled back with the recovery codes, 2FA is not enabled without them
+	_, err := svc.Confirm(context.TODO(), 1, code)
+	assert.Equal(t, gorm.ErrInvalidDB, err)
+	listMock.twoFactorRepo.AssertExpectations(t)
+	listMock.txManager.AssertExpectations(t)
+}
+
+func TestTwoFactorVerifyCode(t *testing.T) {
+	config.Config = &config.Cfg{}
+	config.Config.TwoFactor.Skew = 1
+
+	now := time.Unix(1700000000, 0)
+	confirmedAt := now.Add(-time.Hour)
+	current, _ := totp.Code(rfcSecret, totp.Step(now))
+	stale, _ := totp.Code(rfcSecret, totp.Step(now)-5)
+
+	testCases := []struct {
+		name      string
+		code      string
+		twoFactor *model.TwoFactor
+		mockFunc  func(mock *twoFactorMock)
+		wantErr   error
+	}{
+		{
+			name:      "valid totp code",
+			code:      current,
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret, ConfirmedAt: &confirmedAt},
+			mockFunc: func(listMock *twoFactorMock) {
+				listMock.twoFactorRepo.On("UseStep", mock.Anything, uint32(1), totp.Step(now)).Return(nil)
+			},
+		},
+		{
+			name:      "totp code already used",
+			code:      current,
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret, ConfirmedAt: &confirmedAt, LastUsedStep: totp.Step(now)},
+			wantErr:   service.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:      "totp code used by a concurrent request",
+			code:      current,
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret, ConfirmedAt: &confirmedAt},
+			mockFunc: func(listMock *twoFactorMock) {
+				listMock.twoFactorRepo.On("UseStep", mock.Anything, uint32(1), totp.Step(now)).Return(gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:      "totp code outside of the skew",
+			code:      stale,
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret, ConfirmedAt: &confirmedAt},
+			wantErr:   service.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:      "valid recovery code",
+			code:      "ABCDE-fghij",
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret, ConfirmedAt: &confirmedAt},
+			mockFunc: func(listMock *twoFactorMock) {
+				listMock.twoFactorRepo.On("UseRecoveryCode", mock.Anything, uint32(1), mock.MatchedBy(func(codeHash string) bool {
+					return len(codeHash) == 64
+				})).Return(nil)
+			},
+		},
+		{
+			name:      "recovery code already used",
+			code:      "abcde-fghij",
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret, ConfirmedAt: &confirmedAt},
+			mockFunc: func(listMock *twoFactorMock) {
+				listMock.twoFactorRepo.On("UseRecoveryCode", mock.Anything, uint32(1), mock.Anything).Return(gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:      "not confirmed",
+			code:      current,
+			twoFactor: &model.TwoFactor{UserID: 1, Secret: rfcSecret},
+			wantErr:   service.ErrTwoFactorNotEnabled,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := twoFactorMock{}
+			listMock.twoFactorRepo.On("FindByUserId", mock.Anything, uint32(1)).Return(tc.twoFactor, nil)
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			runInTransaction(&listMock.txManager)
+			svc := service.NewTwoFactorService(&listMock.userRepo, &listMock.twoFactorRepo, &listMock.txManager, service.WithTwoFactorClock(func() time.Time { return now }))
+			err := svc.VerifyCode(context.TODO(), 1, tc.code)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.twoFactorRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -4,18 +4,31 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/go-playground/assert/v2"
 	"github.com/stretchr/testify/mock"
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
 	repoMocks "github.com/si-bas/go-rest-boilerplate/domain/repository/mocks"
 	"github.com/si-bas/go-rest-boilerplate/service"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
 	"github.com/si-bas/go-rest-boilerplate/shared/helper/pagination"
 	"gorm.io/gorm"
 )
 
+// errUserConflict is what the repository returns when the users_EMAIL constraint is violated
+var errUserConflict = custErr.New(custErr.ErrConflict, "user already exists or is still referenced")
+
 type userMock struct {
-	userRepo repoMocks.UserRepository
+	userRepo  repoMocks.UserRepository
+	txManager repoMocks.TxManager
+}
+
+// runInTransaction makes the mocked transaction manager call the function it is given
+func runInTransaction(txManager *repoMocks.TxManager) {
+	txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
 }
 
 func TestUserEmailIsUsed(t *testing.T) {
@@ -50,7 +63,9 @@ func TestUserEmailIsUsed(t *testing.T) {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewUserService(&listMock.userRepo)
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
 			result, err := svc.EmailIsUsed(context.TODO(), "newuser@mail.com")
 
 			assert.Equal(t, tc.wantErr, err)
@@ -82,22 +97,33 @@ func TestUserCreate(t *testing.T) {
 		{
 			name: "success create user",
 			mockFunc: func(listMock *userMock) {
-				countResult := int64(0)
-				listMock.userRepo.On("CountByEmail", mock.Anything, mock.Anything).Return(&countResult, nil)
-				listMock.userRepo.On("Insert", mock.Anything, &model.User{
-					Name:     newUser.Name,
-					Email:    newUser.Email,
-					Password: newUser.Password,
-				}).Return(nil)
+				listMock.userRepo.On("Insert", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Name == newUser.Name &&
+						user.Email == newUser.Email &&
+						user.Password == newUser.Password &&
+						user.EmailVerifiedAt != nil
+				})).Return(nil)
 			},
 		},
 		{
 			name: "failed create user - email already exists",
 			mockFunc: func(listMock *userMock) {
-				countResult := int64(1)
-				listMock.userRepo.On("CountByEmail", mock.Anything, mock.Anything).Return(&countResult, nil)
+				listMock.userRepo.On("Insert", mock.Anything, mock.Anything).Return(errUserConflict)
 			},
-			wantErr: errors.New("email already used"),
+			wantErr: service.ErrEmailAlreadyUsed,
+		},
+		{
+			name: "failed create user - transaction not committed",
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+				listMock.txManager.On("WithinTransaction", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+					if err := fn(ctx); err != nil {
+						return err
+					}
+					return errors.New("commit failed")
+				})
+			},
+			wantErr: errors.New("commit failed"),
 		},
 	}
 
@@ -111,7 +137,9 @@ func TestUserCreate(t *testing.T) {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewUserService(&listMock.userRepo)
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
 			result, err := svc.Create(context.TODO(), newUser)
 
 			assert.Equal(t, tc.wantErr, err)
@@ -119,10 +147,12 @@ func TestUserCreate(t *testing.T) {
 
 			if err == nil {
 				assert.Equal(t, result, model.User{
-					Name:     newUser.Name,
-					Email:    newUser.Email,
-					Password: newUser.Password,
+					Name:            newUser.Name,
+					Email:           newUser.Email,
+					Password:        newUser.Password,
+					EmailVerifiedAt: result.EmailVerifiedAt,
 				})
+				assert.NotEqual(t, nil, result.EmailVerifiedAt)
 			}
 		})
 	}
@@ -165,7 +195,9 @@ func TestUserDetail(t *testing.T) {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewUserService(&listMock.userRepo)
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
 			result, err := svc.Detail(context.TODO(), uint32(1))
 
 			assert.Equal(t, tc.wantErr, err)
@@ -222,7 +254,9 @@ func TestUserListPaginate(t *testing.T) {
 				tc.mockFunc(&listMock)
 			}
 
-			svc := service.NewUserService(&listMock.userRepo)
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
 			result, _, err := svc.ListPaginate(context.TODO(), model.UserFilter{}, pagination.Param{})
 
 			assert.Equal(t, tc.wantErr, err)
@@ -234,3 +268,190 @@ func TestUserListPaginate(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUpdate(t *testing.T) {
+	newName := "updated user"
+	newEmail := "updated@mail.com"
+	newPassword := "new-secret"
+
+	testCases := []struct {
+		name     string
+		payload  model.UpdateUser
+		mockFunc func(mock *userMock)
+		wantErr  error
+	}{
+		{
+			name:    "success update name",
+			payload: model.UpdateUser{Name: &newName},
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1, Name: "user", Email: "user@mail.com"}, nil)
+				listMock.userRepo.On("Update", mock.Anything, &model.User{ID: 1, Name: newName, Email: "user@mail.com"}).Return(nil)
+			},
+		},
+		{
+			name:    "success update email",
+			payload: model.UpdateUser{Email: &newEmail},
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1, Name: "user", Email: "user@mail.com"}, nil)
+				listMock.userRepo.On("Update", mock.Anything, &model.User{ID: 1, Name: "user", Email: newEmail}).Return(nil)
+			},
+		},
+		{
+			name:    "success update password - password is re-hashed",
+			payload: model.UpdateUser{Password: &newPassword},
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1, Name: "user", Email: "user@mail.com"}, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+					return u.Password != newPassword && u.VerifyPassword(newPassword) == nil
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "failed update user - email already exists",
+			payload: model.UpdateUser{Email: &newEmail},
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&model.User{ID: 1, Name: "user", Email: "user@mail.com"}, nil)
+				listMock.userRepo.On("Update", mock.Anything, mock.Anything).Return(errUserConflict)
+			},
+			wantErr: service.ErrEmailAlreadyUsed,
+		},
+		{
+			name:    "failed update user - not found",
+			payload: model.UpdateUser{Name: &newName},
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := userMock{
+				userRepo: repoMocks.UserRepository{},
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
+			result, err := svc.Update(context.TODO(), uint32(1), tc.payload)
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+
+			if err == nil {
+				assert.Equal(t, result.ID, uint32(1))
+			}
+		})
+	}
+}
+
+func TestUserDelete(t *testing.T) {
+	existingUser := model.User{
+		ID:    1,
+		Name:  "user",
+		Email: "user@mail.com",
+	}
+
+	testCases := []struct {
+		name     string
+		mockFunc func(mock *userMock)
+		wantErr  error
+	}{
+		{
+			name: "success delete user",
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(&existingUser, nil)
+				listMock.userRepo.On("Delete", mock.Anything, uint32(1)).Return(nil)
+			},
+		},
+		{
+			name: "failed delete user - not found",
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindById", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := userMock{
+				userRepo: repoMocks.UserRepository{},
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
+			err := svc.Delete(context.TODO(), uint32(1))
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserRestore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mockFunc func(mock *userMock)
+		wantErr  error
+	}{
+		{
+			name: "success restore user",
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindByIdWithDeleted", mock.Anything, uint32(1)).Return(&model.User{
+					ID:        1,
+					DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+				}, nil)
+				listMock.userRepo.On("Restore", mock.Anything, uint32(1)).Return(nil)
+			},
+		},
+		{
+			name: "failed restore user - user is not deleted",
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindByIdWithDeleted", mock.Anything, uint32(1)).Return(&model.User{ID: 1}, nil)
+			},
+			wantErr: service.ErrUserNotDeleted,
+		},
+		{
+			name: "failed restore user - not found",
+			mockFunc: func(listMock *userMock) {
+				listMock.userRepo.On("FindByIdWithDeleted", mock.Anything, uint32(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErr: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			listMock := userMock{
+				userRepo: repoMocks.UserRepository{},
+			}
+			if tc.mockFunc != nil {
+				tc.mockFunc(&listMock)
+			}
+
+			runInTransaction(&listMock.txManager)
+
+			svc := service.NewUserService(&listMock.userRepo, &listMock.txManager)
+			result, err := svc.Restore(context.TODO(), uint32(1))
+
+			assert.Equal(t, tc.wantErr, err)
+			listMock.userRepo.AssertExpectations(t)
+
+			if err == nil {
+				assert.Equal(t, result.DeletedAt.Valid, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/domain/model"
+	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	"github.com/si-bas/go-rest-boilerplate/pkg/totp"
+	"gorm.io/gorm"
+)
+
+type TwoFactorService interface {
+	IsEnabled(context.Context, uint32) (bool, error)
+	Enroll(context.Context, uint32) (*model.TwoFactorEnrollment, error)
+	Confirm(context.Context, uint32, string) (*model.TwoFactorRecoveryCodes, error)
+	Disable(context.Context, uint32, string) error
+	VerifyCode(context.Context, uint32, string) error
+}
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = errors.New("two-factor code is invalid")
+)
+
+type twoFactorImpl struct {
+	userRepo      repository.UserRepository
+	twoFactorRepo repository.TwoFactorRepository
+	txManager     repository.TxManager
+	now           func() time.Time
+}
+
+type TwoFactorOption func(*twoFactorImpl)
+
+// WithTwoFactorClock to replace the clock the codes are checked with
+func WithTwoFactorClock(now func() time.Time) TwoFactorOption {
+	return func(s *twoFactorImpl) {
+		s.now = now
+	}
+}
+
+func NewTwoFactorService(
+	userRepo repository.UserRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	txManager repository.TxManager,
+	opts ...TwoFactorOption) TwoFactorService {
+	s := &twoFactorImpl{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		txManager:     txManager,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *twoFactorImpl) IsEnabled(ctx context.Context, userId uint32) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserId(repository.WithPrimary(ctx), userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return twoFactor.ConfirmedAt != nil, nil
+}
+
+// Enroll generates a new secret, 2FA stays disabled until a code of it is confirmed.
+// Enrolling again before confirming replaces the pending secret.
+func (s *twoFactorImpl) Enroll(ctx context.Context, userId uint32) (*model.TwoFactorEnrollment, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	user, err := s.userRepo.FindById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactorRepo.FindByUserId(ctx, userId)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		twoFactor = &model.TwoFactor{UserID: userId}
+	}
+
+	if twoFactor.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.Secret = secret
+	twoFactor.LastUsedStep = 0
+	if err := s.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	issuer := config.Config.TwoFactor.Issuer
+	if issuer == "" {
+		issuer = config.Config.App.Name
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret:     secret,
+		OtpauthUri: totp.URI(issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables 2FA with a code of the pending secret, the recovery codes are only returned here
+func (s *twoFactorImpl) Confirm(ctx context.Context, userId uint32, code string) (*model.TwoFactorRecoveryCodes, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	twoFactor, err := s.twoFactorRepo.FindByUserId(ctx, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+
+	if twoFactor.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, err := s.useTotpCode(ctx, twoFactor, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	recoveryCodes := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		recoveryCodes = append(recoveryCodes, model.RecoveryCode{
+			UserID:   userId,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	// two-factor is only enabled together with its recovery codes
+	if err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		confirmedAt := s.now()
+		twoFactor.ConfirmedAt = &confirmedAt
+		twoFactor.LastUsedStep = step
+		if err := s.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+			return err
+		}
+
+		return s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userId, recoveryCodes)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// Disable requires a current TOTP code or a recovery code, the secret and the recovery codes are removed
+func (s *twoFactorImpl) Disable(ctx context.Context, userId uint32, code string) error {
+	if err := s.VerifyCode(ctx, userId, code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.Delete(repository.WithPrimary(ctx), userId)
+}
+
+// VerifyCode accepts a TOTP code once, or an unused recovery code which is consumed
+func (s *twoFactorImpl) VerifyCode(ctx context.Context, userId uint32, code string) error {
+	ctx = repository.WithPrimary(ctx)
+
+	twoFactor, err := s.twoFactorRepo.FindByUserId(ctx, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+
+	if twoFactor.ConfirmedAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		_, err := s.useTotpCode(ctx, twoFactor, code)
+		return err
+	}
+
+	if err := s.twoFactorRepo.UseRecoveryCode(ctx, userId, hashToken(normalizeRecoveryCode(code))); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// useTotpCode checks the code and records its step so it cannot be replayed
+func (s *twoFactorImpl) useTotpCode(ctx context.Context, twoFactor *model.TwoFactor, code string) (int64, error) {
+	step, ok := totp.Validate(twoFactor.Secret, strings.TrimSpace(code), s.now(), config.Config.TwoFactor.Skew)
+	if !ok || step <= twoFactor.LastUsedStep {
+		return 0, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.twoFactorRepo.UseStep(ctx, twoFactor.UserID, step); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidTwoFactorCode
+		}
+		return 0, err
+	}
+
+	return step, nil
+}
+
+// randomRecoveryCode is formatted as xxxxx-xxxxx, 50 random bits
+func randomRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// the alphabet has 32 letters, a byte modulo 32 is not biased
+	code := make([]byte, recoveryCodeLength)
+	for i := range b {
+		code[i] = recoveryCodeAlphabet[int(b[i])%len(recoveryCodeAlphabet)]
+	}
+
+	half := recoveryCodeLength / 2
+	return string(code[:half]) + "-" + string(code[half:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
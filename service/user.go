@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/si-bas/go-rest-boilerplate/domain/model"
 	"github.com/si-bas/go-rest-boilerplate/domain/repository"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
 	"github.com/si-bas/go-rest-boilerplate/shared/helper/pagination"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
@@ -14,33 +17,46 @@ type UserService interface {
 	EmailIsUsed(context.Context, string) (bool, error)
 	ListPaginate(context.Context, model.UserFilter, pagination.Param) ([]model.User, *pagination.Param, error)
 	Detail(context.Context, uint32) (*model.User, error)
+	Update(context.Context, uint32, model.UpdateUser) (*model.User, error)
+	Delete(context.Context, uint32) error
+	Restore(context.Context, uint32) (*model.User, error)
 }
 
+var (
+	ErrEmailAlreadyUsed = custErr.New(custErr.ErrConflict, "email already used")
+	ErrUserNotDeleted   = custErr.New(custErr.ErrConflict, "user is not deleted")
+)
+
 type userImpl struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	txManager repository.TxManager
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, txManager repository.TxManager) UserService {
 	return &userImpl{
-		userRepo: userRepo,
+		userRepo:  userRepo,
+		txManager: txManager,
 	}
 }
 
+// Create inserts the user in a transaction, a used email is rejected by the unique index
+// so two requests for the same email cannot both pass a check
 func (s *userImpl) Create(ctx context.Context, payload model.CreateUser) (*model.User, error) {
-	if emailIsUsed, err := s.EmailIsUsed(ctx, payload.Email); emailIsUsed || err != nil {
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New("email already used")
-	}
+	ctx = repository.WithPrimary(ctx)
 
+	// a user added by an admin does not need to verify the email
+	verifiedAt := time.Now()
 	newUser := model.User{
-		Name:     payload.Name,
-		Email:    payload.Email,
-		Password: payload.Password,
+		Name:            payload.Name,
+		Email:           payload.Email,
+		Password:        payload.Password,
+		EmailVerifiedAt: &verifiedAt,
 	}
-	if err := s.userRepo.Insert(ctx, &newUser); err != nil {
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return usedEmail(s.userRepo.Insert(ctx, &newUser))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -73,3 +89,78 @@ func (s *userImpl) Detail(ctx context.Context, id uint32) (*model.User, error) {
 
 	return user, nil
 }
+
+// Update reads and writes the user in one transaction, a used email is rejected by the unique index
+func (s *userImpl) Update(ctx context.Context, id uint32, payload model.UpdateUser) (*model.User, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	var user *model.User
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if payload.Email != nil {
+			user.Email = *payload.Email
+		}
+
+		if payload.Name != nil {
+			user.Name = *payload.Name
+		}
+
+		if payload.Password != nil {
+			user.Password = *payload.Password
+			if err := user.HashPassword(); err != nil {
+				return err
+			}
+		}
+
+		return usedEmail(s.userRepo.Update(ctx, user))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userImpl) Delete(ctx context.Context, id uint32) error {
+	ctx = repository.WithPrimary(ctx)
+
+	if _, err := s.userRepo.FindById(ctx, id); err != nil {
+		return err
+	}
+
+	return s.userRepo.Delete(ctx, id)
+}
+
+func (s *userImpl) Restore(ctx context.Context, id uint32) (*model.User, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	user, err := s.userRepo.FindByIdWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+
+	if err := s.userRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+
+	return user, nil
+}
+
+// usedEmail turns a unique violation into ErrEmailAlreadyUsed, the email is the only unique column of a user
+func usedEmail(err error) error {
+	if errors.Is(err, custErr.ErrConflict) {
+		return ErrEmailAlreadyUsed
+	}
+
+	return err
+}
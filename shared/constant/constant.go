@@ -6,11 +6,31 @@ type ContextKey string
 
 const (
 	AuthorizationHeader = "Authorization"
+	ApiKeyHeader        = "X-API-Key"
+	ApiKeyScheme        = "ApiKey"
 	XRequestIDHeader    = "X-REQUEST-ID"
+	UserAgent           = "UserAgent"
+	ClientIP            = "ClientIP"
 	UserID              = "UserID"
 	User                = "User"
+	SessionID           = "SessionID"
+	TokenID             = "TokenID"
+	ApiKeyID            = "ApiKeyID"
+	Roles               = "Roles"
+	Permissions         = "Permissions"
 	EnvProduction       = "production"
 
+	PermissionUserCreate      = "user:create"
+	PermissionUserRead        = "user:read"
+	PermissionUserUpdate      = "user:update"
+	PermissionUserDelete      = "user:delete"
+	PermissionUserRestore     = "user:restore"
+	PermissionUserReadDeleted = "user:read_deleted"
+	PermissionRoleRead        = "role:read"
+	PermissionRoleAssign      = "role:assign"
+	PermissionTokenRevoke     = "token:revoke"
+	PermissionUserUnlock      = "user:unlock"
+
 	StatusSuccess               = http.StatusOK
 	StatusErrorForm             = http.StatusBadRequest
 	StatusErrorUnknown          = http.StatusBadGateway
@@ -22,6 +42,8 @@ const (
 	StatusInvalidAuthentication = http.StatusProxyAuthRequired
 	StatusNotFound              = http.StatusNotFound
 	StatusConflict              = http.StatusConflict
+	StatusTooManyRequests       = http.StatusTooManyRequests
+	StatusTimeout               = http.StatusGatewayTimeout
 )
 
 var statusMap = map[int][]string{
@@ -36,6 +58,8 @@ var statusMap = map[int][]string{
 	StatusInvalidAuthentication: {"STATUS_INVALID_AUTHENTICATION", "The resource owner or authorization server denied the request"},
 	StatusNotFound:              {"STATUS_NOT_FOUND", "Not Found"},
 	StatusConflict:              {"STATUS_CONFLICT", "Data conflict"},
+	StatusTooManyRequests:       {"STATUS_TOO_MANY_REQUESTS", "Too many requests"},
+	StatusTimeout:               {"STATUS_TIMEOUT", "Request timed out"},
 }
 
 func StatusCode(code int) string {
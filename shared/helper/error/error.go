@@ -2,9 +2,22 @@ package error
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
+// The kinds of domain errors, the error middleware renders each of them with its own status
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+var kinds = []error{ErrNotFound, ErrConflict, ErrValidation, ErrUnauthorized, ErrForbidden, ErrRateLimited}
+
 type ErrChain struct {
 	Message string
 	Cause   error
@@ -24,6 +37,16 @@ func (err ErrChain) Error() string {
 	return fmt.Sprint(err.Message, bcoz, fields)
 }
 
+// Unwrap to reach the cause with errors.Is and errors.As
+func (err ErrChain) Unwrap() error {
+	return err.Cause
+}
+
+// Is reports whether target is the type of the chain or its kind, e.g. errors.Is(err, ErrNotFound)
+func (err ErrChain) Is(target error) bool {
+	return err.Type != nil && (err.Type == target || errors.Is(err.Type, target))
+}
+
 func Type(err error) error {
 	switch err.(type) {
 	case ErrChain:
@@ -60,3 +83,42 @@ func NewInvalidError(msg string) *InvalidError {
 func NewInvalidErrorf(msg string, args ...interface{}) *InvalidError {
 	return NewInvalidError(fmt.Sprintf(msg, args...))
 }
+
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (err *kindError) Error() string {
+	return err.message
+}
+
+func (err *kindError) Is(target error) bool {
+	return err.kind == target
+}
+
+// New to declare a sentinel error of a kind, it can still be compared with ==
+func New(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+// Kind to get the kind of err, nil when it is none of them
+func Kind(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	return nil
+}
+
+// Message to get the message of err that is safe to show to a client
+func Message(err error) string {
+	var chain ErrChain
+	if errors.As(err, &chain) && chain.Message != "" {
+		return chain.Message
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,105 @@
+package response
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+
+	// ErrorFormatProblem makes every error a problem details document, whatever the client accepts
+	ErrorFormatProblem = "problem"
+
+	problemTypeBlank = "about:blank"
+)
+
+// problemTypes names the kind of problem of the codes GetErrorCode gives, the name is appended to the problem type url
+var problemTypes = map[string]string{
+	StatusCodeBadRequest:                "bad-request",
+	StatusCodeUnauthorized:              "unauthorized",
+	StatusCodeForbidden:                 "forbidden",
+	StatusCodeNotFound:                  "not-found",
+	StatusCodeConflict:                  "conflict",
+	StatusCodeGenericPreconditionFailed: "precondition-failed",
+	StatusCodeTooManyRequests:           "too-many-requests",
+	StatusCodeInternalError:             "internal-error",
+	StatusCodeBadGateway:                "dependency-failed",
+	StatusCodeTimeoutError:              "timeout",
+}
+
+// Problem is the RFC 7807 problem details of an error response, code, errors and meta are extension members
+// carrying what the json response has next to its error
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code"`
+	Errors   interface{} `json:"errors,omitempty"`
+	Meta     interface{} `json:"meta,omitempty"`
+}
+
+// Problem to describe the error of the response, instance is the id of the request
+func (r *JSONResponse) Problem(status int, instance string) *Problem {
+	problemType := problemTypeBlank
+	if name, ok := problemTypes[r.Code]; ok && config.Config.App.ProblemTypeUrl != "" {
+		problemType = strings.TrimSuffix(config.Config.App.ProblemTypeUrl, "/") + "/" + name
+	}
+
+	return &Problem{
+		Type:     problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   r.ErrorString,
+		Instance: instance,
+		Code:     r.Code,
+		Errors:   r.Errors,
+		Meta:     r.Meta,
+	}
+}
+
+// WantsProblem tells whether errors are written as problem details, by the config or by the Accept header
+// accepting application/problem+json with a q above 0
+func WantsProblem(accept string) bool {
+	if config.Config.App.ErrorFormat == ErrorFormatProblem {
+		return true
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != ContentTypeProblem {
+			continue
+		}
+
+		// a q of 0 marks the media type as not acceptable
+		if q, ok := params["q"]; ok {
+			if weight, err := strconv.ParseFloat(q, 64); err != nil || weight <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// Render writes the response like c.JSON, an error is written as problem details when they are wanted
+func Render(c *gin.Context, code int, r *JSONResponse) {
+	if code < http.StatusBadRequest || !WantsProblem(c.GetHeader("Accept")) {
+		c.JSON(code, r)
+		return
+	}
+
+	requestID, _ := c.Request.Context().Value(constant.XRequestIDHeader).(string)
+
+	c.Header("Content-Type", ContentTypeProblem)
+	c.JSON(code, r.Problem(code, requestID))
+}
@@ -0,0 +1,128 @@
+package response_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/si-bas/go-rest-boilerplate/config"
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+	custErr "github.com/si-bas/go-rest-boilerplate/shared/helper/error"
+	"github.com/si-bas/go-rest-boilerplate/shared/helper/response"
+)
+
+var errEmailUsed = custErr.New(custErr.ErrConflict, "email already used")
+
+func TestProblemRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name           string
+		errorFormat    string
+		problemTypeUrl string
+		accept         string
+		code           int
+		result         *response.JSONResponse
+		wantProblem    *response.Problem
+	}{
+		{
+			name:   "json by default",
+			accept: "application/json",
+			code:   http.StatusConflict,
+			result: response.NewJSONResponse().APIStatusConflict().SetError(errEmailUsed, "email already used"),
+		},
+		{
+			name:           "problem accepted by the client",
+			problemTypeUrl: "https://api.example.com/problems/",
+			accept:         "application/problem+json, application/json;q=0.9",
+			code:           http.StatusConflict,
+			result:         response.NewJSONResponse().APIStatusConflict().SetError(errEmailUsed, "email already used"),
+			wantProblem: &response.Problem{
+				Type:     "https://api.example.com/problems/conflict",
+				Title:    "Conflict",
+				Status:   http.StatusConflict,
+				Detail:   "email already used",
+				Instance: "request-id",
+				Code:     response.StatusCodeConflict,
+			},
+		},
+		{
+			name:        "problem by config without a type url",
+			errorFormat: response.ErrorFormatProblem,
+			code:        http.StatusTooManyRequests,
+			result:      response.NewJSONResponse().APIStatusTooManyRequests().SetError(response.ErrTooManyRequests, "too many failed logins"),
+			wantProblem: &response.Problem{
+				Type:     "about:blank",
+				Title:    "Too Many Requests",
+				Status:   http.StatusTooManyRequests,
+				Detail:   "too many failed logins",
+				Instance: "request-id",
+				Code:     response.StatusCodeTooManyRequests,
+			},
+		},
+		{
+			name:        "success is never a problem",
+			errorFormat: response.ErrorFormatProblem,
+			code:        http.StatusOK,
+			result:      response.NewJSONResponse().APIStatusSuccess(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			config.Config = &config.Cfg{}
+			config.Config.App.ErrorFormat = tc.errorFormat
+			config.Config.App.ProblemTypeUrl = tc.problemTypeUrl
+
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), constant.XRequestIDHeader, "request-id"))
+			c.Request.Header.Set("Accept", tc.accept)
+
+			response.Render(c, tc.code, tc.result)
+
+			assert.Equal(t, recorder.Code, tc.code)
+			if tc.wantProblem == nil {
+				assert.Equal(t, recorder.Header().Get("Content-Type"), "application/json; charset=utf-8")
+				return
+			}
+
+			var problem response.Problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, recorder.Header().Get("Content-Type"), response.ContentTypeProblem)
+			assert.Equal(t, &problem, tc.wantProblem)
+		})
+	}
+}
+
+func TestWantsProblem(t *testing.T) {
+	config.Config = &config.Cfg{}
+
+	testCases := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "no header", accept: "", want: false},
+		{name: "json only", accept: "application/json", want: false},
+		{name: "problem", accept: "application/problem+json", want: true},
+		{name: "problem among others", accept: "application/json;q=0.9, application/problem+json;q=0.5", want: true},
+		{name: "problem not acceptable", accept: "application/problem+json;q=0, application/json", want: false},
+		{name: "problem not acceptable with decimals", accept: "application/problem+json; q=0.000", want: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, response.WantsProblem(tc.accept), tc.want)
+		})
+	}
+}
@@ -24,6 +24,7 @@ var (
 	ErrUnauthorized        = errors.New("unauthorized")
 	ErrConflict            = errors.New("conflict")
 	ErrDependencyFailed    = errors.New("dependency failed")
+	ErrTooManyRequests     = errors.New("too many requests")
 )
 
 const (
@@ -35,6 +36,7 @@ const (
 	StatusCodeNotFound                  = "404000"
 	StatusCodeConflict                  = "409000"
 	StatusCodeGenericPreconditionFailed = "412000"
+	StatusCodeTooManyRequests           = "429000"
 	StatusCodeOTPLimitReached           = "412550"
 	StatusCodeNoLinkerExist             = "412553"
 	StatusCodeInternalError             = "500000"
@@ -46,9 +48,25 @@ const (
 )
 
 func GetErrorCode(err error) string {
-	err = getErrType(err)
+	if kind := custErr.Kind(err); kind != nil {
+		err = kind
+	} else {
+		err = getErrType(err)
+	}
 
 	switch err {
+	case custErr.ErrNotFound:
+		return StatusCodeNotFound
+	case custErr.ErrConflict:
+		return StatusCodeConflict
+	case custErr.ErrValidation:
+		return StatusCodeBadRequest
+	case custErr.ErrUnauthorized:
+		return StatusCodeUnauthorized
+	case custErr.ErrForbidden:
+		return StatusCodeForbidden
+	case custErr.ErrRateLimited:
+		return StatusCodeTooManyRequests
 	case ErrBadRequest:
 		return StatusCodeBadRequest
 	case ErrForbiddenResource:
@@ -67,6 +85,8 @@ func GetErrorCode(err error) string {
 		return StatusCodeInternalError
 	case ErrTimeoutError:
 		return StatusCodeTimeoutError
+	case ErrTooManyRequests:
+		return StatusCodeTooManyRequests
 	case nil:
 		return StatusCodeGenericSuccess
 	case ErrDependencyFailed:
@@ -94,6 +114,7 @@ type JSONResponse struct {
 	Log         map[string]interface{} `json:"-"`
 	HTMLPage    bool                   `json:"-"`
 	Meta        interface{}            `json:"meta,omitempty"`
+	Errors      interface{}            `json:"errors,omitempty"`
 }
 
 func NewJSONResponse() *JSONResponse {
@@ -120,6 +141,12 @@ func (r *JSONResponse) SetMessage(msg string) *JSONResponse {
 	return r
 }
 
+// SetErrors for the errors of the fields of a request, next to the error of the response
+func (r *JSONResponse) SetErrors(errs interface{}) *JSONResponse {
+	r.Errors = errs
+	return r
+}
+
 func (r *JSONResponse) SetLatency(latency float64) *JSONResponse {
 	r.Latency = fmt.Sprintf("%.2f ms", latency)
 	return r
@@ -268,3 +295,25 @@ func (r *JSONResponse) APIStatusConflict() *JSONResponse {
 	r.Message = constant.StatusText(constant.StatusConflict)
 	return r
 }
+
+// APIStatusTooManyRequests
+func (r *JSONResponse) APIStatusTooManyRequests() *JSONResponse {
+	r.StatusCode = constant.StatusTooManyRequests
+	r.Code = constant.StatusCode(constant.StatusTooManyRequests)
+	r.Message = constant.StatusText(constant.StatusTooManyRequests)
+	return r
+}
+
+// APIStatusTimeout
+func (r *JSONResponse) APIStatusTimeout() *JSONResponse {
+	r.StatusCode = constant.StatusTimeout
+	r.Code = constant.StatusCode(constant.StatusTimeout)
+	r.Message = constant.StatusText(constant.StatusTimeout)
+	return r
+}
+
+// IsTimeout tells whether err comes from the request deadline or the client going away,
+// a driver interrupted by the cancellation does not always return the context error itself
+func IsTimeout(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || ctx.Err() != nil
+}
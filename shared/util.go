@@ -1,6 +1,10 @@
 package shared
 
-import "context"
+import (
+	"context"
+
+	"github.com/si-bas/go-rest-boilerplate/shared/constant"
+)
 
 func GetContextValueAsString(ctx context.Context, key string) string {
 	val, ok := ctx.Value(key).(string)
@@ -10,3 +14,23 @@ func GetContextValueAsString(ctx context.Context, key string) string {
 
 	return ""
 }
+
+func GetContextValueAsStrings(ctx context.Context, key string) []string {
+	val, ok := ctx.Value(key).([]string)
+	if ok {
+		return val
+	}
+
+	return nil
+}
+
+// HasPermission to check whether the authenticated user of ctx is granted the permission
+func HasPermission(ctx context.Context, permission string) bool {
+	for _, p := range GetContextValueAsStrings(ctx, constant.Permissions) {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}